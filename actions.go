@@ -0,0 +1,472 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// ActionsPermissions is the REST response of the actions/permissions endpoint. Organizations report
+// Enabled_repositories and enterprises Enabled_organizations.
+type ActionsPermissions struct {
+	Enabled_repositories  string
+	Enabled_organizations string
+	Allowed_actions       string
+}
+
+type ActionsSelectedActions struct {
+	Github_owned_allowed bool
+	Verified_allowed     bool
+	Patterns_allowed     []string
+}
+
+type ActionsWorkflowPermissions struct {
+	Default_workflow_permissions     string
+	Can_approve_pull_request_reviews bool
+}
+
+type ActionsForkPRContributorApproval struct {
+	Approval_policy string
+}
+
+type ActionsForkPRWorkflows struct {
+	Run_workflows_from_fork_pull_requests  bool
+	Send_write_tokens_to_workflows         bool
+	Send_secrets_and_variables             bool
+	Require_approval_for_fork_pr_workflows bool
+}
+
+// ActionsPolicies are the GitHub Actions settings of an organization or an enterprise. SelectedActions
+// is only collected when Allowed_actions is "selected". The fork pull request policies are nil when
+// the API does not offer them.
+type ActionsPolicies struct {
+	Permissions                 ActionsPermissions
+	SelectedActions             *ActionsSelectedActions
+	Workflow                    ActionsWorkflowPermissions
+	ForkPRContributorApproval   *ActionsForkPRContributorApproval
+	ForkPRWorkflowsPrivateRepos *ActionsForkPRWorkflows
+}
+
+func getOrganizationActionsPolicies(org string) (*ActionsPolicies, error) {
+	return getActionsPolicies(fmt.Sprintf("orgs/%s", org))
+}
+
+func getEnterpriseActionsPolicies(ent string) (*ActionsPolicies, error) {
+	return getActionsPolicies(fmt.Sprintf("enterprises/%s", ent))
+}
+
+// getActionsPolicies collects the Actions settings under an orgs/ or enterprises/ REST path.
+func getActionsPolicies(owner string) (*ActionsPolicies, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := new(ActionsPolicies)
+
+	if err := client.Get(owner+"/actions/permissions", &policies.Permissions); err != nil {
+		return nil, err
+	}
+
+	if policies.Permissions.Allowed_actions == "selected" {
+		policies.SelectedActions = new(ActionsSelectedActions)
+		if err := client.Get(owner+"/actions/permissions/selected-actions", policies.SelectedActions); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := client.Get(owner+"/actions/permissions/workflow", &policies.Workflow); err != nil {
+		return nil, err
+	}
+
+	approval := new(ActionsForkPRContributorApproval)
+	if found, err := getOptional(client, owner+"/actions/permissions/fork-pr-contributor-approval", approval); err != nil {
+		return nil, err
+	} else if found {
+		policies.ForkPRContributorApproval = approval
+	}
+
+	forkWorkflows := new(ActionsForkPRWorkflows)
+	if found, err := getOptional(client, owner+"/actions/permissions/fork-pr-workflows-private-repos", forkWorkflows); err != nil {
+		return nil, err
+	} else if found {
+		policies.ForkPRWorkflowsPrivateRepos = forkWorkflows
+	}
+
+	return policies, nil
+}
+
+// getOptional gets a REST resource that older GitHub versions do not have. It reports false instead
+// of an error when the resource is not found.
+func getOptional(client api.RESTClient, path string, response interface{}) (bool, error) {
+	err := client.Get(path, response)
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// actionsSettings flattens the Actions policies of an organization or an enterprise into named settings.
+func actionsSettings(policies ActionsPolicies) []PolicySetting {
+	var settings []PolicySetting
+
+	if policies.Permissions.Enabled_organizations != "" {
+		settings = append(settings, PolicySetting{"EnabledOrganizations", policies.Permissions.Enabled_organizations})
+	} else {
+		settings = append(settings, PolicySetting{"EnabledRepositories", policies.Permissions.Enabled_repositories})
+	}
+
+	settings = append(settings, PolicySetting{"AllowedActions", policies.Permissions.Allowed_actions})
+
+	if selected := policies.SelectedActions; selected != nil {
+		settings = append(settings,
+			PolicySetting{"GithubOwnedAllowed", strconv.FormatBool(selected.Github_owned_allowed)},
+			PolicySetting{"VerifiedAllowed", strconv.FormatBool(selected.Verified_allowed)},
+			PolicySetting{"PatternsAllowed", strings.Join(selected.Patterns_allowed, ", ")},
+		)
+	}
+
+	settings = append(settings,
+		PolicySetting{"DefaultWorkflowPermissions", policies.Workflow.Default_workflow_permissions},
+		PolicySetting{"CanApprovePullRequestReviews", strconv.FormatBool(policies.Workflow.Can_approve_pull_request_reviews)},
+	)
+
+	if approval := policies.ForkPRContributorApproval; approval != nil {
+		settings = append(settings, PolicySetting{"ForkPRContributorApprovalPolicy", approval.Approval_policy})
+	}
+
+	if forkWorkflows := policies.ForkPRWorkflowsPrivateRepos; forkWorkflows != nil {
+		settings = append(settings, PolicySetting{"ForkPRWorkflowsPrivateRepos", forkPRWorkflowsValue(*forkWorkflows)})
+	}
+
+	return settings
+}
+
+func forkPRWorkflowsValue(forkWorkflows ActionsForkPRWorkflows) string {
+	return fmt.Sprintf("run=%t, write tokens=%t, secrets=%t, require approval=%t",
+		forkWorkflows.Run_workflows_from_fork_pull_requests,
+		forkWorkflows.Send_write_tokens_to_workflows,
+		forkWorkflows.Send_secrets_and_variables,
+		forkWorkflows.Require_approval_for_fork_pr_workflows,
+	)
+}
+
+// actionsCheck compares one Actions policy of an organization with the enterprise policy that will override it.
+type actionsCheck struct {
+	rule    Rule
+	compare func(org *ActionsPolicies, ent *ActionsPolicies) Finding
+}
+
+// actionsScopes are the token scopes needed to collect the data of every Actions check.
+var actionsScopes = []string{"admin:org", "admin:enterprise"}
+
+var actionsChecks = []actionsCheck{
+	{Rule{ID: "actions-enabled", Policy: "Actions Enabled", Category: "actions", Severity: SeverityHigh,
+		Inputs: []string{"enterprise.actions.EnabledOrganizations", "organization.actions.EnabledRepositories"}, Scopes: actionsScopes}, compareActionsEnabled},
+	{Rule{ID: "actions-allowed-actions", Policy: "Allowed Actions", Category: "actions", Severity: SeverityHigh,
+		Inputs: []string{"enterprise.actions.AllowedActions", "enterprise.actions.SelectedActions", "organization.actions.AllowedActions", "organization.actions.SelectedActions"}, Scopes: actionsScopes}, compareAllowedActions},
+	{Rule{ID: "actions-workflow-permissions", Policy: "Default GITHUB_TOKEN Permissions", Category: "actions", Severity: SeverityMedium,
+		Inputs: []string{"enterprise.actions.DefaultWorkflowPermissions", "organization.actions.DefaultWorkflowPermissions"}, Scopes: actionsScopes}, compareWorkflowPermissions},
+	{Rule{ID: "actions-approve-pull-requests", Policy: "Actions Can Approve Pull Requests", Category: "actions", Severity: SeverityLow,
+		Inputs: []string{"enterprise.actions.CanApprovePullRequestReviews", "organization.actions.CanApprovePullRequestReviews"}, Scopes: actionsScopes}, compareApprovePullRequests},
+	{Rule{ID: "actions-fork-pr-contributor-approval", Policy: "Fork Pull Request Workflow Approval", Category: "actions", Severity: SeverityLow,
+		Inputs: []string{"enterprise.actions.ForkPRContributorApprovalPolicy", "organization.actions.ForkPRContributorApprovalPolicy"}, Scopes: actionsScopes}, compareForkPRContributorApproval},
+	{Rule{ID: "actions-fork-pr-workflows-private-repos", Policy: "Fork Pull Request Workflows In Private Repositories", Category: "actions", Severity: SeverityMedium,
+		Inputs: []string{"enterprise.actions.ForkPRWorkflowsPrivateRepos", "organization.actions.ForkPRWorkflowsPrivateRepos"}, Scopes: actionsScopes}, compareForkPRWorkflowsPrivateRepos},
+}
+
+// auditActions collects the Actions policies of an organization and an enterprise and compares them.
+// When they cannot be collected every Actions check is reported as unknown.
+func auditActions(org string, ent string) ([]Finding, []Inventory) {
+	orgActions, err := getOrganizationActionsPolicies(org)
+	if err != nil {
+		return uncollectedActionsFindings(err), nil
+	}
+
+	entActions, err := getEnterpriseActionsPolicies(ent)
+	if err != nil {
+		return uncollectedActionsFindings(err), nil
+	}
+
+	return compareActionsPolicies(orgActions, entActions), []Inventory{
+		settingsInventory("Actions policies: "+org, actionsSettings(*orgActions)),
+		settingsInventory("Actions policies: "+ent, actionsSettings(*entActions)),
+	}
+}
+
+// compareActionsPolicies returns one finding per Actions check.
+func compareActionsPolicies(org *ActionsPolicies, ent *ActionsPolicies) []Finding {
+	fmt.Println("Comparing Organization and Enterprise Actions Policies")
+
+	findings := make([]Finding, 0, len(actionsChecks))
+	for _, check := range actionsChecks {
+		findings = append(findings, withRuleMetadata(check.rule, check.compare(org, ent)))
+	}
+
+	return findings
+}
+
+// uncollectedActionsFindings reports every Actions check as unknown when the policies could not be collected.
+func uncollectedActionsFindings(err error) []Finding {
+	findings := make([]Finding, 0, len(actionsChecks))
+	for _, check := range actionsChecks {
+		findings = append(findings, uncollectedFinding(check.rule, err))
+	}
+
+	return findings
+}
+
+func compareActionsEnabled(org *ActionsPolicies, ent *ActionsPolicies) Finding {
+	setting := ent.Permissions.Enabled_organizations
+	orgSetting := org.Permissions.Enabled_repositories
+	finding := Finding{
+		SourceValue:    orgSetting,
+		TargetValue:    setting,
+		EffectiveValue: orgSetting,
+	}
+
+	if setting == "" || setting == "all" {
+		finding.Comment = "The Enterprise allows GitHub Actions in every Organization. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if orgSetting == "none" {
+		finding.Comment = "GitHub Actions are disabled in the Organization."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = "none"
+	finding.Status = statusFail
+
+	if setting == "none" {
+		finding.Comment = "The Enterprise disables GitHub Actions in every Organization. Workflows in the Organization will stop running."
+		finding.Remediation = "Allow GitHub Actions in the Enterprise policies, or plan how the Organization's workflows will run after the transfer."
+		return finding
+	}
+
+	finding.Comment = "The Enterprise only allows GitHub Actions in selected Organizations. Workflows in the Organization will stop running until it is selected."
+	finding.Remediation = "Add the Organization to the Enterprise's selected Organizations for GitHub Actions right after the transfer."
+
+	return finding
+}
+
+// allowedActionsRank orders the allowed_actions values from the least to the most restrictive.
+var allowedActionsRank = map[string]int{
+	"all":        0,
+	"selected":   1,
+	"local_only": 2,
+}
+
+func compareAllowedActions(org *ActionsPolicies, ent *ActionsPolicies) Finding {
+	setting := ent.Permissions.Allowed_actions
+	orgSetting := org.Permissions.Allowed_actions
+	finding := Finding{
+		SourceValue:    allowedActionsValue(org),
+		TargetValue:    allowedActionsValue(ent),
+		EffectiveValue: allowedActionsValue(org),
+	}
+
+	if setting == "" || setting == "all" || org.Permissions.Enabled_repositories == "none" {
+		finding.Comment = "The Enterprise does not restrict which actions the Organization can use. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if allowedActionsRank[setting] > allowedActionsRank[orgSetting] {
+		finding.EffectiveValue = allowedActionsValue(ent)
+		finding.Comment = fmt.Sprintf("The Enterprise only allows %s actions, the Organization allows %s actions. Workflows using other actions will fail.", setting, orgSetting)
+		finding.Status = statusFail
+		finding.Remediation = "Review the actions the Organization's workflows use and allow them in the Enterprise before the transfer."
+		return finding
+	}
+
+	if setting != "selected" || orgSetting != "selected" || org.SelectedActions == nil || ent.SelectedActions == nil {
+		finding.Comment = "The Organization allows the same or fewer actions than the Enterprise."
+		finding.Status = statusPass
+		return finding
+	}
+
+	// both only allow selected actions, the Organization may only use what the Enterprise also selects
+	var missing []string
+	if org.SelectedActions.Github_owned_allowed && !ent.SelectedActions.Github_owned_allowed {
+		missing = append(missing, "actions created by GitHub")
+	}
+	if org.SelectedActions.Verified_allowed && !ent.SelectedActions.Verified_allowed {
+		missing = append(missing, "actions by verified creators")
+	}
+
+	allowed := make(map[string]bool)
+	for _, pattern := range ent.SelectedActions.Patterns_allowed {
+		allowed[pattern] = true
+	}
+	for _, pattern := range org.SelectedActions.Patterns_allowed {
+		if !allowed[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+
+	if len(missing) == 0 {
+		finding.Comment = "Every action the Organization allows is also allowed by the Enterprise."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = allowedActionsValue(ent)
+	finding.Comment = fmt.Sprintf("The Enterprise does not allow %s.", strings.Join(missing, ", "))
+	finding.Status = statusFail
+	finding.Remediation = fmt.Sprintf("Add %s to the Enterprise's allowed actions before the transfer.", strings.Join(missing, ", "))
+
+	return finding
+}
+
+func allowedActionsValue(policies *ActionsPolicies) string {
+	if policies.SelectedActions == nil {
+		return policies.Permissions.Allowed_actions
+	}
+
+	selected := policies.SelectedActions
+
+	return fmt.Sprintf("selected (github owned=%t, verified=%t, patterns=%s)", selected.Github_owned_allowed, selected.Verified_allowed, strings.Join(selected.Patterns_allowed, ", "))
+}
+
+func compareWorkflowPermissions(org *ActionsPolicies, ent *ActionsPolicies) Finding {
+	setting := ent.Workflow.Default_workflow_permissions
+	orgSetting := org.Workflow.Default_workflow_permissions
+	finding := Finding{
+		SourceValue:    orgSetting,
+		TargetValue:    setting,
+		EffectiveValue: orgSetting,
+	}
+
+	if setting != "read" || orgSetting != "write" {
+		finding.Comment = "The Enterprise does not reduce the default GITHUB_TOKEN permissions of the Organization."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = setting
+	finding.Comment = "The Enterprise restricts the default GITHUB_TOKEN to read access. Workflows that rely on write access without a permissions block will fail."
+	finding.Status = statusFail
+	finding.Remediation = "Add explicit permissions blocks to the workflows that write with the GITHUB_TOKEN before the transfer."
+
+	return finding
+}
+
+func compareApprovePullRequests(org *ActionsPolicies, ent *ActionsPolicies) Finding {
+	setting := ent.Workflow.Can_approve_pull_request_reviews
+	orgSetting := org.Workflow.Can_approve_pull_request_reviews
+	finding := Finding{
+		SourceValue:    strconv.FormatBool(orgSetting),
+		TargetValue:    strconv.FormatBool(setting),
+		EffectiveValue: strconv.FormatBool(orgSetting),
+	}
+
+	if setting || !orgSetting {
+		finding.Comment = "The Enterprise does not stop GitHub Actions from approving pull requests in the Organization."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = "false"
+	finding.Comment = "The Enterprise does not allow GitHub Actions to create or approve pull requests. Workflows in the Organization that do so will fail."
+	finding.Status = statusFail
+	finding.Remediation = "Find the workflows that create or approve pull requests and plan a replacement, such as a GitHub App token."
+
+	return finding
+}
+
+// forkPRApprovalRank orders the fork pull request approval policies from the least to the most restrictive.
+var forkPRApprovalRank = map[string]int{
+	"first_time_contributors_new_to_github": 0,
+	"first_time_contributors":               1,
+	"all_external_contributors":             2,
+}
+
+func compareForkPRContributorApproval(org *ActionsPolicies, ent *ActionsPolicies) Finding {
+	if org.ForkPRContributorApproval == nil || ent.ForkPRContributorApproval == nil {
+		return Finding{
+			Comment: "The fork pull request approval policy is not available on this GitHub version.",
+			Status:  statusUnknown,
+		}
+	}
+
+	setting := ent.ForkPRContributorApproval.Approval_policy
+	orgSetting := org.ForkPRContributorApproval.Approval_policy
+	finding := Finding{
+		SourceValue:    orgSetting,
+		TargetValue:    setting,
+		EffectiveValue: orgSetting,
+	}
+
+	if forkPRApprovalRank[setting] <= forkPRApprovalRank[orgSetting] {
+		finding.Comment = "The Organization requires the same or more approvals for fork pull request workflows than the Enterprise."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = setting
+	finding.Comment = fmt.Sprintf("The Enterprise requires approval to run fork pull request workflows for %s. More contributors will wait for a maintainer to approve their workflows.", strings.ReplaceAll(setting, "_", " "))
+	finding.Status = statusFail
+	finding.Remediation = "Tell repository maintainers that they will have to approve more fork pull request workflows."
+
+	return finding
+}
+
+func compareForkPRWorkflowsPrivateRepos(org *ActionsPolicies, ent *ActionsPolicies) Finding {
+	if org.ForkPRWorkflowsPrivateRepos == nil || ent.ForkPRWorkflowsPrivateRepos == nil {
+		return Finding{
+			Comment: "The fork pull request workflow policy for private repositories is not available on this GitHub version.",
+			Status:  statusUnknown,
+		}
+	}
+
+	setting := *ent.ForkPRWorkflowsPrivateRepos
+	orgSetting := *org.ForkPRWorkflowsPrivateRepos
+
+	// the Enterprise can only take permissions away, or add the approval requirement
+	effective := ActionsForkPRWorkflows{
+		Run_workflows_from_fork_pull_requests:  orgSetting.Run_workflows_from_fork_pull_requests && setting.Run_workflows_from_fork_pull_requests,
+		Send_write_tokens_to_workflows:         orgSetting.Send_write_tokens_to_workflows && setting.Send_write_tokens_to_workflows,
+		Send_secrets_and_variables:             orgSetting.Send_secrets_and_variables && setting.Send_secrets_and_variables,
+		Require_approval_for_fork_pr_workflows: orgSetting.Require_approval_for_fork_pr_workflows || setting.Require_approval_for_fork_pr_workflows,
+	}
+
+	finding := Finding{
+		SourceValue:    forkPRWorkflowsValue(orgSetting),
+		TargetValue:    forkPRWorkflowsValue(setting),
+		EffectiveValue: forkPRWorkflowsValue(effective),
+	}
+
+	var changes []string
+	if effective.Run_workflows_from_fork_pull_requests != orgSetting.Run_workflows_from_fork_pull_requests {
+		changes = append(changes, "workflows will no longer run on fork pull requests")
+	} else if orgSetting.Run_workflows_from_fork_pull_requests {
+		if effective.Send_write_tokens_to_workflows != orgSetting.Send_write_tokens_to_workflows {
+			changes = append(changes, "fork pull request workflows will only get read tokens")
+		}
+		if effective.Send_secrets_and_variables != orgSetting.Send_secrets_and_variables {
+			changes = append(changes, "fork pull request workflows will no longer get secrets and variables")
+		}
+		if effective.Require_approval_for_fork_pr_workflows != orgSetting.Require_approval_for_fork_pr_workflows {
+			changes = append(changes, "fork pull request workflows will need approval")
+		}
+	}
+
+	if len(changes) == 0 {
+		finding.Comment = "The Enterprise does not restrict fork pull request workflows in the Organization's private repositories further."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("In private repositories, %s.", strings.Join(changes, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Tell maintainers of private repositories that accept fork pull requests how their workflows will change."
+
+	return finding
+}
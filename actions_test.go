@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestCompareActionsPolicies(t *testing.T) {
+	org := &ActionsPolicies{
+		Permissions: ActionsPermissions{Enabled_repositories: "all", Allowed_actions: "selected"},
+		SelectedActions: &ActionsSelectedActions{
+			Github_owned_allowed: true,
+			Patterns_allowed:     []string{"octo-org/*", "docker/login-action@*"},
+		},
+		Workflow: ActionsWorkflowPermissions{Default_workflow_permissions: "write", Can_approve_pull_request_reviews: true},
+	}
+	ent := &ActionsPolicies{
+		Permissions: ActionsPermissions{Enabled_organizations: "all", Allowed_actions: "selected"},
+		SelectedActions: &ActionsSelectedActions{
+			Github_owned_allowed: true,
+			Patterns_allowed:     []string{"octo-org/*"},
+		},
+		Workflow: ActionsWorkflowPermissions{Default_workflow_permissions: "read", Can_approve_pull_request_reviews: true},
+	}
+
+	statuses := make(map[string]Finding)
+	for _, finding := range compareActionsPolicies(org, ent) {
+		statuses[finding.RuleID] = finding
+	}
+
+	if len(statuses) != len(actionsChecks) {
+		t.Fatalf("expected a finding per Actions check, got %d", len(statuses))
+	}
+
+	tests := map[string]string{
+		"actions-enabled":                         statusPass,
+		"actions-allowed-actions":                 statusFail,
+		"actions-workflow-permissions":            statusFail,
+		"actions-approve-pull-requests":           statusPass,
+		"actions-fork-pr-contributor-approval":    statusUnknown,
+		"actions-fork-pr-workflows-private-repos": statusUnknown,
+	}
+
+	for id, want := range tests {
+		if got := statuses[id]; got.Status != want {
+			t.Errorf("%s: expected %s, got %+v", id, want, got)
+		}
+	}
+
+	if remediation := statuses["actions-allowed-actions"].Remediation; remediation != "Add docker/login-action@* to the Enterprise's allowed actions before the transfer." {
+		t.Errorf("unexpected remediation %q", remediation)
+	}
+	if severity := statuses["actions-workflow-permissions"].Severity; severity != SeverityMedium {
+		t.Errorf("expected the rule severity on a failed finding, got %s", severity)
+	}
+}
+
+func TestCompareActionsEnabled(t *testing.T) {
+	org := &ActionsPolicies{Permissions: ActionsPermissions{Enabled_repositories: "selected"}}
+
+	for setting, want := range map[string]string{"all": statusPass, "none": statusFail, "selected": statusFail} {
+		ent := &ActionsPolicies{Permissions: ActionsPermissions{Enabled_organizations: setting}}
+		if finding := compareActionsEnabled(org, ent); finding.Status != want {
+			t.Errorf("%s: expected %s, got %+v", setting, want, finding)
+		}
+	}
+}
+
+func TestCompareForkPRWorkflowsPrivateRepos(t *testing.T) {
+	org := &ActionsPolicies{ForkPRWorkflowsPrivateRepos: &ActionsForkPRWorkflows{
+		Run_workflows_from_fork_pull_requests: true,
+		Send_secrets_and_variables:            true,
+	}}
+	ent := &ActionsPolicies{ForkPRWorkflowsPrivateRepos: &ActionsForkPRWorkflows{
+		Run_workflows_from_fork_pull_requests:  true,
+		Require_approval_for_fork_pr_workflows: true,
+	}}
+
+	finding := compareForkPRWorkflowsPrivateRepos(org, ent)
+	if finding.Status != statusFail {
+		t.Fatalf("expected the stricter Enterprise policy to fail, got %+v", finding)
+	}
+
+	want := "In private repositories, fork pull request workflows will no longer get secrets and variables, fork pull request workflows will need approval."
+	if finding.Comment != want {
+		t.Errorf("unexpected comment %q", finding.Comment)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// AppInstallation is a GitHub App installed on an organization. IpAllowList is nil when the app's
+// own IP allow list could not be read, which is the case for apps the token cannot see.
+type AppInstallation struct {
+	Id                   int64
+	App_id               int64
+	App_slug             string
+	Repository_selection string
+	Permissions          map[string]string
+	Suspended_at         string
+	IpAllowList          *IpAllowListEntries `json:"-"`
+}
+
+// permissions returns the app's permissions sorted by name, for example "contents:read, issues:write".
+func (a AppInstallation) permissions() string {
+	permissions := make([]string, 0, len(a.Permissions))
+	for name, access := range a.Permissions {
+		permissions = append(permissions, name+":"+access)
+	}
+	sort.Strings(permissions)
+
+	return strings.Join(permissions, ", ")
+}
+
+type appIpAllowListPage struct {
+	Node struct {
+		App struct {
+			IpAllowListEntries IpAllowListEntries `graphql:"ipAllowListEntries(first: $first, after: $after)"`
+		} `graphql:"... on App"`
+	} `graphql:"node(id: $id)"`
+}
+
+// getOrganizationAppInstallations lists the GitHub Apps installed on an organization with their own IP allow lists.
+func getOrganizationAppInstallations(org string) ([]AppInstallation, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gqlClient, err := gh.GQLClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var installations []AppInstallation
+
+	err = getAllPages(client, fmt.Sprintf("orgs/%s/installations?per_page=%d", org, restPageSize), func(body []byte) error {
+		var page struct {
+			Installations []AppInstallation
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		installations = append(installations, page.Installations...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range installations {
+		// an app whose allow list the token may not read is reported with an unknown impact
+		allowList, err := getAppIpAllowList(client, gqlClient, installations[i].App_slug)
+		if err != nil && !isPermissionError(err) {
+			return nil, err
+		}
+		installations[i].IpAllowList = allowList
+	}
+
+	return installations, nil
+}
+
+// getAppIpAllowList fetches every IP allow list entry a GitHub App defines.
+func getAppIpAllowList(client api.RESTClient, gqlClient api.GQLClient, slug string) (*IpAllowListEntries, error) {
+	var app struct {
+		Node_id string
+	}
+	if err := client.Get(fmt.Sprintf("apps/%s", slug), &app); err != nil {
+		return nil, err
+	}
+
+	entries := new(IpAllowListEntries)
+	var after *graphql.String
+
+	for {
+		page := new(appIpAllowListPage)
+
+		variables := map[string]interface{}{
+			"id":    graphql.ID(app.Node_id),
+			"first": graphql.Int(ipAllowListPageSize),
+			"after": after,
+		}
+
+		if err := gqlClient.Query("AppIpAllowListEntries", page, variables); err != nil {
+			return nil, err
+		}
+
+		entries.Nodes = append(entries.Nodes, page.Node.App.IpAllowListEntries.Nodes...)
+
+		pageInfo := page.Node.App.IpAllowListEntries.PageInfo
+		if !pageInfo.HasNextPage {
+			return entries, nil
+		}
+
+		after = graphql.NewString(graphql.String(pageInfo.EndCursor))
+	}
+}
+
+// Impact of the enterprise IP allow list on a GitHub App installation.
+const (
+	appImpactNone        = "not affected"
+	appImpactSuspended   = "suspended"
+	appImpactOwnList     = "allowed by its own IP allow list"
+	appImpactCovered     = "covered by the Enterprise allow list"
+	appImpactLosesAccess = "loses access"
+	appImpactNoList      = "blocked outside the Enterprise allow list"
+	appImpactUnknown     = "unknown"
+)
+
+// appIpAllowListImpact predicts whether an installed app keeps access once the enterprise IP allow
+// list applies to the organization.
+func appIpAllowListImpact(installation AppInstallation, ent *EnterprisePolicies) string {
+	ownerInfo := ent.Enterprise.OwnerInfo
+
+	switch {
+	case installation.Suspended_at != "":
+		return appImpactSuspended
+	case ownerInfo.IpAllowListEnabledSetting != "ENABLED":
+		return appImpactNone
+	case installation.IpAllowList == nil:
+		return appImpactUnknown
+	case len(installation.IpAllowList.Nodes) == 0:
+		return appImpactNoList
+	case ownerInfo.IpAllowListForInstalledAppsEnabledSetting == "ENABLED":
+		return appImpactOwnList
+	}
+
+	for _, coverage := range analyzeIpAllowListCoverage(*installation.IpAllowList, ownerInfo.IpAllowListEntries) {
+		if coverage.Entry.IsActive && coverage.Coverage != coverageCovered {
+			return appImpactLosesAccess
+		}
+	}
+
+	return appImpactCovered
+}
+
+// appInstallationsInventory lists the GitHub Apps installed on an organization. The impact column is
+// only filled in when an enterprise is given.
+func appInstallationsInventory(org string, installations []AppInstallation, ent *EnterprisePolicies) Inventory {
+	inventory := Inventory{
+		Name:    "GitHub App installations: " + org,
+		Columns: []string{"App", "Installation", "Repositories", "Permissions", "Suspended", "IP Allow List", "Impact"},
+	}
+
+	for _, installation := range installations {
+		allowList := "unknown"
+		if installation.IpAllowList != nil {
+			allowList = installation.IpAllowList.values()
+		}
+
+		impact := ""
+		if ent != nil {
+			impact = appIpAllowListImpact(installation, ent)
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			installation.App_slug,
+			fmt.Sprint(installation.Id),
+			installation.Repository_selection,
+			installation.permissions(),
+			installation.Suspended_at,
+			allowList,
+			impact,
+		})
+	}
+
+	return inventory
+}
+
+var appInstallationsRule = Rule{
+	ID:       "app-installations-ip-allow-list",
+	Policy:   "GitHub App Access Through The IP Allow List",
+	Category: "network",
+	Severity: SeverityHigh,
+	Inputs:   []string{"enterprise.IpAllowListEnabledSetting", "enterprise.IpAllowListEntries", "enterprise.IpAllowListForInstalledAppsEnabledSetting", "organization.IpAllowListEnabledSetting", "organization.installations"},
+	Scopes:   []string{"read:org"},
+}
+
+// auditAppInstallations collects the GitHub Apps installed on an organization and predicts which of
+// them lose access once the enterprise IP allow list applies.
+func auditAppInstallations(org string, orgPolicies *OrganizationPolicies, entPolicies *EnterprisePolicies) ([]Finding, []Inventory) {
+	installations, err := getOrganizationAppInstallations(org)
+	if err != nil {
+		return []Finding{uncollectedFinding(appInstallationsRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(appInstallationsRule, compareAppInstallations(installations, orgPolicies, entPolicies))
+
+	return []Finding{finding}, []Inventory{appInstallationsInventory(org, installations, entPolicies)}
+}
+
+func compareAppInstallations(installations []AppInstallation, org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	var losing, unlisted, unknown []string
+	for _, installation := range installations {
+		switch appIpAllowListImpact(installation, ent) {
+		case appImpactLosesAccess:
+			losing = append(losing, installation.App_slug)
+		case appImpactNoList:
+			unlisted = append(unlisted, installation.App_slug)
+		case appImpactUnknown:
+			unknown = append(unknown, installation.App_slug)
+		}
+	}
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d installed apps", len(installations)),
+		TargetValue:    ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting,
+		EffectiveValue: strings.Join(append(losing, unlisted...), ", "),
+	}
+
+	// apps without an allow list of their own were already restricted by an Organization allow list
+	if org.GQL.Organization.IpAllowListEnabledSetting == "ENABLED" {
+		unlisted = nil
+		finding.EffectiveValue = strings.Join(losing, ", ")
+	}
+
+	var problems []string
+	if len(losing) > 0 {
+		problems = append(problems, fmt.Sprintf("%s connect from addresses outside the Enterprise allow list and will lose access.", strings.Join(losing, ", ")))
+	}
+	if len(unlisted) > 0 {
+		problems = append(problems, fmt.Sprintf("%s have no IP allow list of their own and will be blocked unless they connect from the Enterprise allow list.", strings.Join(unlisted, ", ")))
+	}
+
+	if len(problems) > 0 {
+		finding.Comment = strings.Join(problems, " ")
+		finding.Status = statusFail
+		finding.Remediation = "Add the addresses of these apps to the Enterprise allow list, or enable the IP allow list for installed GitHub Apps in the Enterprise, before the transfer."
+		return finding
+	}
+
+	if len(unknown) > 0 {
+		finding.Comment = fmt.Sprintf("Could not read the IP allow lists of %s.", strings.Join(unknown, ", "))
+		finding.Status = statusUnknown
+		finding.Remediation = "Ask the owners of these apps which addresses they connect from."
+		return finding
+	}
+
+	finding.Comment = "No installed GitHub App will lose access through the Enterprise IP allow list."
+	finding.Status = statusPass
+
+	return finding
+}
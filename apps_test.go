@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestAppIpAllowListImpact(t *testing.T) {
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting = "ENABLED"
+	ent.Enterprise.OwnerInfo.IpAllowListEntries = testIpAllowListEntries("192.0.2.0/24")
+
+	covered := testIpAllowListEntries("192.0.2.10")
+	outside := testIpAllowListEntries("192.0.2.10", "198.51.100.0/24")
+	empty := testIpAllowListEntries()
+
+	tests := []struct {
+		installation AppInstallation
+		want         string
+	}{
+		{AppInstallation{App_slug: "covered", IpAllowList: &covered}, appImpactCovered},
+		{AppInstallation{App_slug: "outside", IpAllowList: &outside}, appImpactLosesAccess},
+		{AppInstallation{App_slug: "empty", IpAllowList: &empty}, appImpactNoList},
+		{AppInstallation{App_slug: "private"}, appImpactUnknown},
+		{AppInstallation{App_slug: "suspended", Suspended_at: "2026-01-02T03:04:05Z"}, appImpactSuspended},
+	}
+
+	for _, test := range tests {
+		if got := appIpAllowListImpact(test.installation, ent); got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.installation.App_slug, test.want, got)
+		}
+	}
+
+	ent.Enterprise.OwnerInfo.IpAllowListForInstalledAppsEnabledSetting = "ENABLED"
+	if got := appIpAllowListImpact(tests[1].installation, ent); got != appImpactOwnList {
+		t.Errorf("expected apps to keep access through their own allow list, got %q", got)
+	}
+}
+
+func TestCompareAppInstallations(t *testing.T) {
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting = "ENABLED"
+	ent.Enterprise.OwnerInfo.IpAllowListEntries = testIpAllowListEntries("192.0.2.0/24")
+
+	outside := testIpAllowListEntries("198.51.100.0/24")
+	empty := testIpAllowListEntries()
+	installations := []AppInstallation{
+		{App_slug: "deployer", IpAllowList: &outside, Permissions: map[string]string{"issues": "write", "contents": "read"}},
+		{App_slug: "linter", IpAllowList: &empty},
+	}
+
+	org := new(OrganizationPolicies)
+
+	finding := compareAppInstallations(installations, org, ent)
+	if finding.Status != statusFail || finding.EffectiveValue != "deployer, linter" {
+		t.Errorf("expected both apps to be flagged, got %+v", finding)
+	}
+
+	// an Organization allow list already restricts apps without one of their own
+	org.GQL.Organization.IpAllowListEnabledSetting = "ENABLED"
+
+	finding = compareAppInstallations(installations, org, ent)
+	if finding.EffectiveValue != "deployer" {
+		t.Errorf("expected only the app connecting from outside the Enterprise allow list, got %+v", finding)
+	}
+
+	if permissions := installations[0].permissions(); permissions != "contents:read, issues:write" {
+		t.Errorf("unexpected permissions %q", permissions)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Baseline is the organization posture an enterprise requires, keyed by the organizationSettings names.
+// It can be written in YAML or JSON, for example:
+//
+//	name: octo-enterprise
+//	settings:
+//	  RequiresTwoFactorAuthentication: true
+//	  DefaultRepositoryPermission: [none, read]
+//	  MembersCanCreatePublicRepositories: false
+//	  IpAllowListEnabledSetting: ENABLED
+type Baseline struct {
+	Name     string                   `yaml:"name"`
+	Settings map[string]AllowedValues `yaml:"settings"`
+}
+
+// AllowedValues is the set of values a baseline accepts for a setting. It is written as a single
+// value or as a list of values.
+type AllowedValues []string
+
+func (a *AllowedValues) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		var values []string
+		if err := value.Decode(&values); err != nil {
+			return err
+		}
+
+		*a = values
+
+		return nil
+	}
+
+	var single string
+	if err := value.Decode(&single); err != nil {
+		return err
+	}
+
+	*a = AllowedValues{single}
+
+	return nil
+}
+
+// allows reports whether value is one of the allowed values, ignoring case.
+func (a AllowedValues) allows(value string) bool {
+	for _, allowed := range a {
+		if strings.EqualFold(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadBaseline reads a YAML or JSON baseline file.
+func loadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline %s: %w", path, err)
+	}
+
+	baseline := new(Baseline)
+	if err := yaml.Unmarshal(data, baseline); err != nil {
+		return nil, fmt.Errorf("could not parse baseline %s: %w", path, err)
+	}
+
+	if baseline.Name == "" {
+		baseline.Name = path
+	}
+
+	return baseline, nil
+}
+
+// checkBaseline checks an organization's settings against a baseline. Settings the baseline does not
+// mention are not reported. Settings the baseline names but the organization does not report are unknown.
+func checkBaseline(org *OrganizationPolicies, baseline *Baseline) []Finding {
+	fmt.Println("Checking Organization Policies against baseline", baseline.Name)
+
+	var findings []Finding
+	known := make(map[string]bool)
+
+	for _, setting := range organizationSettings(*org) {
+		known[setting.Key] = true
+
+		if allowed, ok := baseline.Settings[setting.Key]; ok {
+			findings = append(findings, checkBaselineSetting(setting.Key, setting.Value, allowed))
+		}
+	}
+
+	var unknownKeys []string
+	for key := range baseline.Settings {
+		if !known[key] {
+			unknownKeys = append(unknownKeys, key)
+		}
+	}
+	sort.Strings(unknownKeys)
+
+	for _, key := range unknownKeys {
+		findings = append(findings, Finding{
+			RuleID:      "baseline:" + key,
+			Policy:      key,
+			Category:    "baseline",
+			Severity:    SeverityInfo,
+			TargetValue: strings.Join(baseline.Settings[key], ", "),
+			Comment:     fmt.Sprintf("%s is not a setting this tool collects.", key),
+			Status:      statusUnknown,
+		})
+	}
+
+	return findings
+}
+
+func checkBaselineSetting(key string, value string, allowed AllowedValues) Finding {
+	meta, ok := organizationSettingMetadata[key]
+	if !ok {
+		meta = settingMetadata{"organization", SeverityLow}
+	}
+
+	finding := Finding{
+		RuleID:         "baseline:" + key,
+		Policy:         key,
+		Category:       meta.Category,
+		Severity:       SeverityInfo,
+		SourceValue:    value,
+		TargetValue:    strings.Join(allowed, ", "),
+		EffectiveValue: value,
+	}
+
+	switch {
+	case value == "":
+		finding.Comment = fmt.Sprintf("The Organization did not report %s. It may require Organization owner access.", key)
+		finding.Status = statusUnknown
+		finding.Severity = meta.Severity
+	case allowed.allows(value):
+		finding.Comment = fmt.Sprintf("%s conforms to the baseline.", key)
+		finding.Status = statusPass
+	default:
+		finding.Comment = fmt.Sprintf("%s is %q, the baseline requires %s.", key, value, finding.TargetValue)
+		finding.Remediation = fmt.Sprintf("Change %s to %s.", key, finding.TargetValue)
+		finding.Status = statusFail
+		finding.Severity = meta.Severity
+	}
+
+	return finding
+}
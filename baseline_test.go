@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeBaseline(t *testing.T, name string, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestCheckBaseline(t *testing.T) {
+	path := writeBaseline(t, "baseline.yml", `
+name: octo-enterprise
+settings:
+  RequiresTwoFactorAuthentication: true
+  DefaultRepositoryPermission: [none, read]
+  MembersCanCreatePublicRepositories: false
+  MembersCanCreatePages: false
+  IpAllowListEnabledSetting: ENABLED
+  RequiresCoffee: true
+`)
+
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := false
+	org := new(OrganizationPolicies)
+	org.GQL.Organization.RequiresTwoFactorAuthentication = true
+	org.REST.Default_repository_permission = "write"
+	org.REST.Members_can_create_public_repositories = &disabled
+
+	byID := make(map[string]Finding)
+	for _, finding := range checkBaseline(org, baseline) {
+		byID[finding.RuleID] = finding
+	}
+
+	tests := []struct {
+		id     string
+		status string
+	}{
+		{"baseline:RequiresTwoFactorAuthentication", statusPass},
+		{"baseline:DefaultRepositoryPermission", statusFail},
+		{"baseline:MembersCanCreatePublicRepositories", statusPass},
+		{"baseline:MembersCanCreatePages", statusUnknown},
+		{"baseline:IpAllowListEnabledSetting", statusUnknown},
+		{"baseline:RequiresCoffee", statusUnknown},
+	}
+
+	if len(byID) != len(tests) {
+		t.Errorf("expected %d findings, got %d", len(tests), len(byID))
+	}
+
+	for _, tt := range tests {
+		if got := byID[tt.id].Status; got != tt.status {
+			t.Errorf("%s status = %q, want %q (%s)", tt.id, got, tt.status, byID[tt.id].Comment)
+		}
+	}
+
+	if got := byID["baseline:DefaultRepositoryPermission"].Severity; got != SeverityHigh {
+		t.Errorf("DefaultRepositoryPermission severity = %s, want high", got)
+	}
+}
+
+func TestLoadBaselineJSON(t *testing.T) {
+	path := writeBaseline(t, "baseline.json", `{"settings": {"DefaultRepositoryPermission": ["none", "read"], "RequiresTwoFactorAuthentication": "true"}}`)
+
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if baseline.Name != path {
+		t.Errorf("expected the baseline to be named after its file, got %q", baseline.Name)
+	}
+	if got := baseline.Settings["DefaultRepositoryPermission"]; len(got) != 2 || !got.allows("READ") {
+		t.Errorf("unexpected allowed values %v", got)
+	}
+}
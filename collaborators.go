@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// Collaboration is an outside collaborator's access to a repository, or a pending invitation to it.
+// Inviter and Invited are only known for pending invitations.
+type Collaboration struct {
+	Login      string
+	Repository string
+	Permission string
+	Pending    bool
+	Inviter    string
+	Invited    string
+}
+
+// Collaborations are the outside collaborations of an organization and the logins of its owners.
+type Collaborations struct {
+	Collaborations []Collaboration
+	Owners         map[string]bool
+}
+
+// getOrganizationCollaborations lists the outside collaborators and pending collaborator invitations
+// of every repository of an organization.
+func getOrganizationCollaborations(org string, repositories []Repository) (*Collaborations, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	owners, err := getLogins(client, fmt.Sprintf("orgs/%s/members?role=admin&per_page=%d", org, restPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	collaborations := &Collaborations{Owners: make(map[string]bool)}
+	for _, owner := range owners {
+		collaborations.Owners[owner] = true
+	}
+
+	for _, repository := range repositories {
+		active, err := getOutsideCollaborators(client, repository.Full_name)
+		if err != nil {
+			return nil, err
+		}
+
+		pending, err := getRepositoryInvitations(client, repository.Full_name)
+		if err != nil {
+			return nil, err
+		}
+
+		collaborations.Collaborations = append(collaborations.Collaborations, active...)
+		collaborations.Collaborations = append(collaborations.Collaborations, pending...)
+	}
+
+	return collaborations, nil
+}
+
+func getOutsideCollaborators(client api.RESTClient, repository string) ([]Collaboration, error) {
+	var collaborations []Collaboration
+
+	err := getAllPages(client, fmt.Sprintf("repos/%s/collaborators?affiliation=outside&per_page=%d", repository, restPageSize), func(body []byte) error {
+		var page []struct {
+			Login     string
+			Role_name string
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, collaborator := range page {
+			collaborations = append(collaborations, Collaboration{
+				Login:      collaborator.Login,
+				Repository: repository,
+				Permission: collaborator.Role_name,
+			})
+		}
+
+		return nil
+	})
+
+	return collaborations, err
+}
+
+func getRepositoryInvitations(client api.RESTClient, repository string) ([]Collaboration, error) {
+	var collaborations []Collaboration
+
+	err := getAllPages(client, fmt.Sprintf("repos/%s/invitations?per_page=%d", repository, restPageSize), func(body []byte) error {
+		var page []struct {
+			Invitee struct {
+				Login string
+			}
+			Email   string
+			Inviter struct {
+				Login string
+			}
+			Permissions string
+			Created_at  string
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, invitation := range page {
+			// invitations sent by email have no invitee yet
+			login := invitation.Invitee.Login
+			if login == "" {
+				login = invitation.Email
+			}
+
+			collaborations = append(collaborations, Collaboration{
+				Login:      login,
+				Repository: repository,
+				Permission: invitation.Permissions,
+				Pending:    true,
+				Inviter:    invitation.Inviter.Login,
+				Invited:    invitation.Created_at,
+			})
+		}
+
+		return nil
+	})
+
+	return collaborations, err
+}
+
+// collaborationImpact predicts how the enterprise collaborator invitation policy affects a collaboration.
+func collaborationImpact(collaboration Collaboration, owners map[string]bool, ent *EnterprisePolicies) string {
+	if ent == nil {
+		return ""
+	}
+
+	if ent.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting != "DISABLED" {
+		return "no change"
+	}
+
+	if !collaboration.Pending {
+		return "new invitations need an owner"
+	}
+
+	if !owners[collaboration.Inviter] {
+		return "not sent by an owner, check it stays valid"
+	}
+
+	return "no change"
+}
+
+// collaborationsInventory lists the outside collaborations of an organization. The impact column is
+// only filled in when an enterprise is given.
+func collaborationsInventory(org string, collaborations *Collaborations, ent *EnterprisePolicies) Inventory {
+	inventory := Inventory{
+		Name:    "Outside collaborators: " + org,
+		Columns: []string{"Login", "Repository", "Permission", "Status", "Invited By", "Invited", "After Transfer"},
+	}
+
+	for _, collaboration := range collaborations.Collaborations {
+		status := "active"
+		if collaboration.Pending {
+			status = "pending"
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			collaboration.Login,
+			collaboration.Repository,
+			collaboration.Permission,
+			status,
+			collaboration.Inviter,
+			collaboration.Invited,
+			collaborationImpact(collaboration, collaborations.Owners, ent),
+		})
+	}
+
+	return inventory
+}
+
+var collaboratorInvitationsRule = Rule{
+	ID:       "outside-collaborator-invitations",
+	Policy:   "Outside Collaborator Invitations",
+	Category: "member",
+	Severity: SeverityMedium,
+	Inputs:   []string{"enterprise.MembersCanInviteCollaboratorsSetting", "organization.outside_collaborators", "organization.repository_invitations"},
+	Scopes:   []string{"read:org", "repo"},
+}
+
+// auditCollaborators lists the outside collaborations of an organization and checks them against the
+// enterprise collaborator invitation policy.
+func auditCollaborators(org string, repositories []Repository, ent *EnterprisePolicies) ([]Finding, []Inventory) {
+	collaborations, err := getOrganizationCollaborations(org, repositories)
+	if err != nil {
+		return []Finding{uncollectedFinding(collaboratorInvitationsRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(collaboratorInvitationsRule, compareCollaborations(collaborations, ent))
+
+	return []Finding{finding}, []Inventory{collaborationsInventory(org, collaborations, ent)}
+}
+
+func compareCollaborations(collaborations *Collaborations, ent *EnterprisePolicies) Finding {
+	// invitations an owner sent stay allowed, only those from repository admins are stopped by the policy
+	var nonOwnerInvitations []string
+	repositories := make(map[string]bool)
+	collaborators := make(map[string]bool)
+
+	for _, collaboration := range collaborations.Collaborations {
+		repositories[collaboration.Repository] = true
+		collaborators[collaboration.Login] = true
+
+		if collaboration.Pending && !collaborations.Owners[collaboration.Inviter] {
+			nonOwnerInvitations = append(nonOwnerInvitations, fmt.Sprintf("%s to %s", collaboration.Login, collaboration.Repository))
+		}
+	}
+
+	setting := ent.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d outside collaborators in %d repositories", len(collaborators), len(repositories)),
+		TargetValue:    setting,
+		EffectiveValue: "repository admins can invite",
+	}
+
+	if setting != "DISABLED" {
+		finding.Comment = "The Enterprise lets repository admins invite outside collaborators. Existing collaborations and invitations are not affected."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = "only owners can invite"
+
+	if len(repositories) == 0 {
+		finding.Comment = "The Organization has no outside collaborators or pending collaborator invitations."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("Only Organization owners will be able to invite outside collaborators. New invitations to %s will need an owner.", strings.Join(sortedKeys(repositories), ", "))
+
+	if len(nonOwnerInvitations) == 0 {
+		finding.Comment += " Existing collaborators and invitations sent by an owner are not affected."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment += fmt.Sprintf(" The pending invitations of %s were not sent by an owner.", strings.Join(nonOwnerInvitations, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Check whether these pending invitations stay valid after the transfer, and have an owner send them again if not. Tell repository admins that Organization owners will handle outside collaborator invitations."
+
+	return finding
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestCompareCollaborations(t *testing.T) {
+	collaborations := &Collaborations{
+		Owners: map[string]bool{"monalisa": true},
+		Collaborations: []Collaboration{
+			{Login: "contractor", Repository: "octodemo/site", Permission: "write"},
+			{Login: "designer", Repository: "octodemo/site", Permission: "read", Pending: true, Inviter: "hubot"},
+			{Login: "auditor", Repository: "octodemo/api", Permission: "read", Pending: true, Inviter: "monalisa"},
+		},
+	}
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting = "ENABLED"
+
+	if finding := compareCollaborations(collaborations, ent); finding.Status != statusPass {
+		t.Errorf("expected no impact when repository admins can invite, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting = "DISABLED"
+
+	finding := compareCollaborations(collaborations, ent)
+	want := "Only Organization owners will be able to invite outside collaborators. New invitations to octodemo/api, octodemo/site will need an owner. The pending invitations of designer to octodemo/site were not sent by an owner."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	owned := &Collaborations{Owners: collaborations.Owners, Collaborations: []Collaboration{collaborations.Collaborations[0], collaborations.Collaborations[2]}}
+	if finding := compareCollaborations(owned, ent); finding.Status != statusPass {
+		t.Errorf("expected collaborators and owner invitations to pass, got %+v", finding)
+	}
+
+	rows := collaborationsInventory("octodemo", collaborations, ent).Rows
+	impacts := []string{"new invitations need an owner", "not sent by an owner, check it stays valid", "no change"}
+	for i, impact := range impacts {
+		if rows[i][6] != impact {
+			t.Errorf("row %d: expected %q, got %v", i, impact, rows[i])
+		}
+	}
+}
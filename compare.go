@@ -0,0 +1,862 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// enforcedSetting describes an enterprise ENABLED / DISABLED / NO_POLICY setting. When org is nil the
+// organization value is not collected, so the comparison can only explain what the enterprise will enforce.
+type enforcedSetting struct {
+	id       string
+	policy   string
+	category string
+	severity Severity
+	input    string
+	value    func(ent *EnterprisePolicies) string
+	orgInput string
+	org      func(org *OrganizationPolicies) bool
+	enabled  string
+	disabled string
+}
+
+var enforcedSettings = []enforcedSetting{
+	{
+		id:       "members-can-change-repository-visibility",
+		policy:   "Members Can Change Repository Visibility",
+		category: "repository",
+		severity: SeverityLow,
+		input:    "enterprise.MembersCanChangeRepositoryVisibilitySetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.MembersCanChangeRepositoryVisibilitySetting
+		},
+		enabled:  "The Enterprise allows members with admin permissions to change repository visibility. Organization owners will no longer be able to restrict this.",
+		disabled: "The Enterprise only allows Organization owners to change repository visibility. Repository admins in the Organization will lose this ability.",
+	},
+	{
+		id:       "members-can-delete-issues",
+		policy:   "Members Can Delete Issues",
+		category: "repository",
+		severity: SeverityLow,
+		input:    "enterprise.MembersCanDeleteIssuesSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.MembersCanDeleteIssuesSetting
+		},
+		enabled:  "The Enterprise allows members with admin permissions to delete issues. Organization owners will no longer be able to restrict this.",
+		disabled: "The Enterprise only allows Organization owners to delete issues. Repository admins in the Organization will lose this ability.",
+	},
+	{
+		id:       "members-can-delete-repositories",
+		policy:   "Members Can Delete Repositories",
+		category: "repository",
+		severity: SeverityMedium,
+		input:    "enterprise.MembersCanDeleteRepositoriesSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.MembersCanDeleteRepositoriesSetting
+		},
+		enabled:  "The Enterprise allows members with admin permissions to delete or transfer repositories. Organization owners will no longer be able to restrict this.",
+		disabled: "The Enterprise only allows Organization owners to delete or transfer repositories. Repository admins in the Organization will lose this ability.",
+	},
+	{
+		id:       "members-can-invite-collaborators",
+		policy:   "Members Can Invite Outside Collaborators",
+		category: "member",
+		severity: SeverityMedium,
+		input:    "enterprise.MembersCanInviteCollaboratorsSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting
+		},
+		enabled:  "The Enterprise allows repository admins to invite outside collaborators. Organization owners will no longer be able to restrict this.",
+		disabled: "The Enterprise only allows Organization owners to invite outside collaborators. Repository admins in the Organization will no longer be able to invite them.",
+	},
+	{
+		id:       "members-can-update-protected-branches",
+		policy:   "Members Can Update Protected Branches",
+		category: "repository",
+		severity: SeverityMedium,
+		input:    "enterprise.MembersCanUpdateProtectedBranchesSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.MembersCanUpdateProtectedBranchesSetting
+		},
+		enabled:  "The Enterprise allows repository admins to update protected branch settings in the Organization.",
+		disabled: "The Enterprise prevents repository admins from updating protected branch settings. Only Organization owners will be able to change them.",
+	},
+	{
+		id:       "members-can-view-dependency-insights",
+		policy:   "Members Can View Dependency Insights",
+		category: "member",
+		severity: SeverityLow,
+		input:    "enterprise.MembersCanViewDependencyInsightsSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.MembersCanViewDependencyInsightsSetting
+		},
+		enabled:  "The Enterprise allows all Organization members to view dependency insights.",
+		disabled: "The Enterprise restricts dependency insights to Organization owners. Members will lose access to them.",
+	},
+	{
+		id:       "organization-projects",
+		policy:   "Organization Projects",
+		category: "organization",
+		severity: SeverityLow,
+		input:    "enterprise.OrganizationProjectsSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.OrganizationProjectsSetting
+		},
+		orgInput: "organization.Has_organization_projects",
+		org: func(org *OrganizationPolicies) bool {
+			return org.REST.Has_organization_projects
+		},
+		enabled:  "The Enterprise enables organization projects. Organization owners will no longer be able to disable them.",
+		disabled: "The Enterprise disables organization projects. Existing organization projects will no longer be accessible.",
+	},
+	{
+		id:       "repository-projects",
+		policy:   "Repository Projects",
+		category: "repository",
+		severity: SeverityLow,
+		input:    "enterprise.RepositoryProjectsSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.RepositoryProjectsSetting
+		},
+		orgInput: "organization.Has_repository_projects",
+		org: func(org *OrganizationPolicies) bool {
+			return org.REST.Has_repository_projects
+		},
+		enabled:  "The Enterprise enables repository projects. Organization owners will no longer be able to disable them.",
+		disabled: "The Enterprise disables repository projects. Existing repository projects will no longer be accessible.",
+	},
+	{
+		id:       "team-discussions",
+		policy:   "Team Discussions",
+		category: "organization",
+		severity: SeverityLow,
+		input:    "enterprise.TeamDiscussionsSetting",
+		value: func(ent *EnterprisePolicies) string {
+			return ent.Enterprise.OwnerInfo.TeamDiscussionsSetting
+		},
+		enabled:  "The Enterprise enables team discussions. Organization owners will no longer be able to disable them.",
+		disabled: "The Enterprise disables team discussions. Existing team discussions will no longer be accessible.",
+	},
+}
+
+func init() {
+	registerRule(Rule{
+		ID:       "private-repository-forking",
+		Policy:   "Private Repository Forking",
+		Category: "repository",
+		Severity: SeverityMedium,
+		Inputs:   []string{"enterprise.AllowPrivateRepositoryForkingSetting", "enterprise.AllowPrivateRepositoryForkingSettingPolicyValue", "organization.MembersCanForkPrivateRepositories"},
+		Evaluate: comparePrivateRepositoryForking,
+	})
+
+	registerRule(Rule{
+		ID:       "default-repository-permission",
+		Policy:   "Default Repository Permission Setting",
+		Category: "repository",
+		Severity: SeverityHigh,
+		Inputs:   []string{"enterprise.DefaultRepositoryPermissionSetting", "organization.Default_repository_permission"},
+		Evaluate: compareDefaultRepositoryPermission,
+	})
+
+	registerRule(Rule{
+		ID:       "members-can-create-repositories",
+		Policy:   "Members Can Create Repositories",
+		Category: "repository",
+		Severity: SeverityMedium,
+		Inputs:   []string{"enterprise.MembersCanCreateRepositoriesSetting", "organization.Members_can_create_public_repositories", "organization.Members_can_create_private_repositories", "organization.Members_can_create_internal_repositories"},
+		Evaluate: compareMembersCanCreateRepositories,
+	})
+
+	for _, setting := range enforcedSettings {
+		setting := setting
+		inputs := []string{setting.input}
+		if setting.orgInput != "" {
+			inputs = append(inputs, setting.orgInput)
+		}
+
+		registerRule(Rule{
+			ID:       setting.id,
+			Policy:   setting.policy,
+			Category: setting.category,
+			Severity: setting.severity,
+			Inputs:   inputs,
+			Evaluate: func(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+				return compareEnforcedSetting(setting, org, ent)
+			},
+		})
+	}
+
+	registerRule(Rule{
+		ID:       "members-can-make-purchases",
+		Policy:   "Members Can Make Purchases",
+		Category: "billing",
+		Severity: SeverityLow,
+		Inputs:   []string{"enterprise.MembersCanMakePurchasesSetting"},
+		Evaluate: compareMembersCanMakePurchases,
+	})
+
+	registerRule(Rule{
+		ID:       "ip-allow-list",
+		Policy:   "IP Allow List",
+		Category: "network",
+		Severity: SeverityHigh,
+		Inputs:   []string{"enterprise.IpAllowListEnabledSetting", "organization.IpAllowListEnabledSetting"},
+		Evaluate: compareIpAllowList,
+	})
+
+	registerRule(Rule{
+		ID:       "ip-allow-list-entries",
+		Policy:   "IP Allow List Entries",
+		Category: "network",
+		Severity: SeverityBlocker,
+		Inputs:   []string{"enterprise.IpAllowListEntries", "organization.IpAllowListEntries"},
+		Evaluate: compareIpAllowListEntries,
+	})
+
+	registerRule(Rule{
+		ID:       "ip-allow-list-inactive-entries",
+		Policy:   "IP Allow List Inactive Entries",
+		Category: "network",
+		Severity: SeverityMedium,
+		Inputs:   []string{"enterprise.IpAllowListEntries", "organization.IpAllowListEntries"},
+		Evaluate: compareIpAllowListInactiveEntries,
+	})
+
+	registerRule(Rule{
+		ID:       "ip-allow-list-redundant-entries",
+		Policy:   "IP Allow List Redundant Entries",
+		Category: "network",
+		Severity: SeverityLow,
+		Inputs:   []string{"enterprise.IpAllowListEntries", "organization.IpAllowListEntries"},
+		Evaluate: compareIpAllowListRedundancies,
+	})
+
+	registerRule(Rule{
+		ID:       "ip-allow-list-installed-apps",
+		Policy:   "IP Allow List For Installed Apps",
+		Category: "network",
+		Severity: SeverityHigh,
+		Inputs:   []string{"enterprise.IpAllowListForInstalledAppsEnabledSetting", "organization.IpAllowListForInstalledAppsEnabledSetting", "organization.IpAllowListEnabledSetting"},
+		Evaluate: compareIpAllowListForInstalledApps,
+	})
+
+	registerRule(Rule{
+		ID:       "notification-delivery-restriction",
+		Policy:   "Notification Delivery Restriction",
+		Category: "member",
+		Severity: SeverityMedium,
+		Inputs:   []string{"enterprise.NotificationDeliveryRestrictionEnabledSetting", "organization.NotificationDeliveryRestrictionEnabledSetting"},
+		Evaluate: compareNotificationDeliveryRestriction,
+	})
+
+	registerRule(Rule{
+		ID:       "two-factor-authentication",
+		Policy:   "Two Factor Authentication Setting",
+		Category: "account",
+		Severity: SeverityBlocker,
+		Inputs:   []string{"enterprise.TwoFactorRequiredSetting", "organization.RequiresTwoFactorAuthentication"},
+		Evaluate: compareTwoFactorAuthentication,
+	})
+
+	registerRule(Rule{
+		ID:       "saml-identity-provider",
+		Policy:   "SAML Identity Provider",
+		Category: "account",
+		Severity: SeverityBlocker,
+		Inputs:   []string{"enterprise.SamlIdentityProvider", "organization.SamlIdentityProvider"},
+		Evaluate: compareSamlIdentityProvider,
+	})
+}
+
+func comparePolicies(org *OrganizationPolicies, ent *EnterprisePolicies) []Finding {
+	fmt.Println("Comparing Organization and Enterprise Policies")
+
+	return runRules(org, ent)
+}
+
+// noEnterprisePolicy reports whether an enterprise setting leaves the decision to its organizations.
+func noEnterprisePolicy(value string) bool {
+	return value == "" || value == "NO_POLICY"
+}
+
+// effectiveValue returns the value the organization will have after the transfer: its own value when
+// the enterprise has no policy, otherwise the value the enterprise enforces.
+func effectiveValue(entSetting string, orgValue string, enforced string) string {
+	if noEnterprisePolicy(entSetting) {
+		return orgValue
+	}
+
+	return enforced
+}
+
+func compareEnforcedSetting(setting enforcedSetting, org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	value := setting.value(ent)
+	finding := Finding{TargetValue: value}
+
+	if setting.org != nil {
+		finding.SourceValue = strconv.FormatBool(setting.org(org))
+	}
+
+	if noEnterprisePolicy(value) {
+		finding.EffectiveValue = finding.SourceValue
+		finding.Comment = "There is no Enterprise policy. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if value != "ENABLED" && value != "DISABLED" {
+		finding.Comment = fmt.Sprintf("Unrecognized Enterprise setting %q.", value)
+		finding.Status = statusUnknown
+		return finding
+	}
+
+	entEnabled := value == "ENABLED"
+	finding.EffectiveValue = strconv.FormatBool(entEnabled)
+	finding.Remediation = fmt.Sprintf("Tell Organization owners and repository admins that the Enterprise %s policy will apply after the transfer.", strings.ToLower(setting.policy))
+
+	if setting.org == nil {
+		finding.Comment = setting.disabled
+		if entEnabled {
+			finding.Comment = setting.enabled
+		}
+		finding.Status = statusUnknown
+		return finding
+	}
+
+	if setting.org(org) == entEnabled {
+		finding.Comment = fmt.Sprintf("The Enterprise and the Organization both have %s %s.", strings.ToLower(setting.policy), strings.ToLower(value))
+		finding.Status = statusPass
+		finding.Remediation = ""
+		return finding
+	}
+
+	finding.Comment = setting.disabled
+	if entEnabled {
+		finding.Comment = setting.enabled
+	}
+	finding.Status = statusFail
+
+	return finding
+}
+
+func comparePrivateRepositoryForking(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting
+	orgValue := strconv.FormatBool(org.GQL.Organization.MembersCanForkPrivateRepositories)
+	finding := Finding{
+		SourceValue:    orgValue,
+		TargetValue:    setting,
+		EffectiveValue: effectiveValue(setting, orgValue, strconv.FormatBool(setting == "ENABLED")),
+	}
+
+	if noEnterprisePolicy(setting) {
+		finding.Comment = "There is no Enterprise policy."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if setting == "ENABLED" {
+		if org.GQL.Organization.MembersCanForkPrivateRepositories {
+			finding.Comment = "The Enterprise and the Organization both allow forking of private repositories."
+			finding.Status = statusPass
+		} else {
+			finding.Comment = "The Enterprise allows forking of private repositories. Organization members will be able to fork private and internal repositories after the transfer."
+			finding.Status = statusFail
+			finding.Remediation = "Review which private repositories should not be forked and restrict access to them before the transfer."
+		}
+
+		if policyValue := ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSettingPolicyValue; policyValue != "" {
+			finding.TargetValue = fmt.Sprintf("%s (%s)", setting, policyValue)
+			finding.Comment += fmt.Sprintf(" Forks are allowed to: %s.", policyValue)
+		}
+
+		return finding
+	}
+
+	if setting == "DISABLED" {
+		if org.GQL.Organization.MembersCanForkPrivateRepositories {
+			finding.Comment = "The Enterprise does not allow forking of private repositories. Organization members will no longer be able to fork private and internal repositories."
+			finding.Status = statusFail
+			finding.Remediation = "Identify existing private forks and the workflows that depend on them before the transfer."
+		} else {
+			finding.Comment = "The Enterprise and the Organization both disallow forking of private repositories."
+			finding.Status = statusPass
+		}
+
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("Unrecognized Enterprise setting %q.", setting)
+	finding.Status = statusUnknown
+
+	return finding
+}
+
+// repositoryPermissionRank orders base repository permissions from least to most access.
+var repositoryPermissionRank = map[string]int{
+	"none":  0,
+	"read":  1,
+	"write": 2,
+	"admin": 3,
+}
+
+func compareDefaultRepositoryPermission(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := strings.ToLower(ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting)
+	orgPermission := strings.ToLower(org.REST.Default_repository_permission)
+	finding := Finding{
+		SourceValue:    orgPermission,
+		TargetValue:    ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting,
+		EffectiveValue: effectiveValue(ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting, orgPermission, setting),
+	}
+
+	if noEnterprisePolicy(ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting) {
+		finding.Comment = "There is no Enterprise policy. The Organization base repository permission will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if orgPermission == "" {
+		finding.Comment = "The Organization did not report its base repository permission. It may require Organization owner access."
+		finding.Status = statusUnknown
+		return finding
+	}
+
+	if setting == orgPermission {
+		finding.Comment = fmt.Sprintf("The Enterprise and the Organization both use %s as the base repository permission.", setting)
+		finding.Status = statusPass
+		return finding
+	}
+
+	if repositoryPermissionRank[setting] > repositoryPermissionRank[orgPermission] {
+		finding.Comment = fmt.Sprintf("The Enterprise raises the base repository permission from %s to %s. Every Organization member will gain %s access to all repositories.", orgPermission, setting, setting)
+		finding.Remediation = "Move repositories that must stay restricted to private visibility with explicit team access before the transfer."
+	} else {
+		finding.Comment = fmt.Sprintf("The Enterprise lowers the base repository permission from %s to %s. Members who rely on the base permission will lose access unless they are granted it through a team.", orgPermission, setting)
+		finding.Remediation = fmt.Sprintf("Grant %s access through teams to the members who need it before the transfer.", orgPermission)
+	}
+	finding.Status = statusFail
+
+	return finding
+}
+
+// enterpriseRepositoryCreation lists the repository visibilities members may create under an
+// enterprise MembersCanCreateRepositoriesSetting.
+func enterpriseRepositoryCreation(setting string) map[string]bool {
+	switch setting {
+	case "ALL":
+		return map[string]bool{"public": true, "private": true, "internal": true}
+	case "PRIVATE":
+		return map[string]bool{"private": true}
+	case "PUBLIC":
+		return map[string]bool{"public": true}
+	}
+
+	return map[string]bool{}
+}
+
+// repositoryCreationValue formats the repository visibilities members may create, for example "public, private".
+func repositoryCreationValue(allowed map[string]bool) string {
+	var visibilities []string
+	for _, visibility := range []string{"public", "private", "internal"} {
+		if allowed[visibility] {
+			visibilities = append(visibilities, visibility)
+		}
+	}
+
+	if len(visibilities) == 0 {
+		return "none"
+	}
+
+	return strings.Join(visibilities, ", ")
+}
+
+func compareMembersCanCreateRepositories(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting
+	allowed := enterpriseRepositoryCreation(setting)
+	current := map[string]bool{
+		"public":   optionalBool(org.REST.Members_can_create_public_repositories) == "true",
+		"private":  optionalBool(org.REST.Members_can_create_private_repositories) == "true",
+		"internal": optionalBool(org.REST.Members_can_create_internal_repositories) == "true",
+	}
+	finding := Finding{
+		SourceValue:    repositoryCreationValue(current),
+		TargetValue:    setting,
+		EffectiveValue: effectiveValue(setting, repositoryCreationValue(current), repositoryCreationValue(allowed)),
+	}
+
+	if noEnterprisePolicy(setting) {
+		finding.Comment = "There is no Enterprise policy. The Organization repository creation setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if org.REST.Members_can_create_public_repositories == nil && org.REST.Members_can_create_private_repositories == nil {
+		finding.Comment = "The Organization did not report which repository visibilities members can create. It may require Organization owner access."
+		finding.Status = statusUnknown
+		return finding
+	}
+
+	var gained, lost []string
+	for _, visibility := range []string{"public", "private", "internal"} {
+		if allowed[visibility] && !current[visibility] {
+			gained = append(gained, visibility)
+		}
+		if !allowed[visibility] && current[visibility] {
+			lost = append(lost, visibility)
+		}
+	}
+
+	if len(gained) == 0 && len(lost) == 0 {
+		finding.Comment = "The Enterprise and the Organization allow members to create the same repository visibilities."
+		finding.Status = statusPass
+		return finding
+	}
+
+	var changes []string
+	if len(gained) > 0 {
+		changes = append(changes, fmt.Sprintf("Members will be able to create %s repositories.", strings.Join(gained, ", ")))
+	}
+	if len(lost) > 0 {
+		changes = append(changes, fmt.Sprintf("Members will no longer be able to create %s repositories.", strings.Join(lost, ", ")))
+	}
+
+	finding.Comment = strings.Join(changes, " ")
+	finding.Status = statusFail
+	finding.Remediation = "Update repository creation guidance for Organization members before the transfer."
+
+	return finding
+}
+
+func compareMembersCanMakePurchases(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.MembersCanMakePurchasesSetting
+	finding := Finding{
+		TargetValue:    setting,
+		EffectiveValue: setting,
+	}
+
+	if setting == "DISABLED" {
+		finding.Comment = "The Enterprise does not allow Organization owners to make purchases. Marketplace purchases and plan changes will have to go through the Enterprise."
+		finding.Status = statusFail
+		finding.Remediation = "Move pending Marketplace purchases and plan changes to Enterprise owners."
+		return finding
+	}
+
+	finding.Comment = "Organization owners can keep making purchases. They will be billed to the Enterprise account."
+	finding.Status = statusPass
+
+	return finding
+}
+
+func compareIpAllowList(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting
+	orgSetting := org.GQL.Organization.IpAllowListEnabledSetting
+	finding := Finding{
+		SourceValue:    orgSetting,
+		TargetValue:    setting,
+		EffectiveValue: orgSetting,
+	}
+
+	if setting != "ENABLED" {
+		finding.Comment = "The Enterprise IP allow list is not enabled. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = setting
+
+	if orgSetting == "ENABLED" {
+		finding.Comment = "The Enterprise and the Organization both have an IP allow list enabled. The Enterprise allow list will also apply to the Organization."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = "The Enterprise IP allow list will apply to the Organization. Access from addresses outside the Enterprise allow list will be blocked."
+	finding.Status = statusFail
+	finding.Remediation = "Collect the addresses Organization members and integrations connect from and add them to the Enterprise allow list before the transfer."
+
+	return finding
+}
+
+func compareIpAllowListEntries(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	orgEntries := org.GQL.Organization.IpAllowListEntries
+	entEntries := ent.Enterprise.OwnerInfo.IpAllowListEntries
+	finding := Finding{
+		SourceValue:    orgEntries.values(),
+		TargetValue:    entEntries.values(),
+		EffectiveValue: orgEntries.values(),
+	}
+
+	if ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting != "ENABLED" || len(orgEntries.Nodes) == 0 {
+		finding.Comment = "No Organization allow list entries are affected by the Enterprise allow list."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if org.GQL.Organization.IpAllowListEnabledSetting != "ENABLED" {
+		finding.Comment = "The Organization allow list is disabled, so none of its entries grant access today."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = entEntries.values()
+
+	var uncovered, partial, invalid []string
+	for _, coverage := range analyzeIpAllowListCoverage(orgEntries, entEntries) {
+		switch {
+		case coverage.Coverage == coverageInvalid:
+			invalid = append(invalid, coverage.Entry.AllowListValue)
+		case !coverage.Entry.IsActive:
+		case coverage.Coverage == coverageUncovered:
+			uncovered = append(uncovered, coverage.Entry.AllowListValue)
+		case coverage.Coverage == coveragePartial:
+			partial = append(partial, coverage.Entry.AllowListValue)
+		}
+	}
+
+	var problems []string
+	if len(uncovered) > 0 {
+		problems = append(problems, fmt.Sprintf("The Organization allows %s, which the Enterprise allow list does not cover.", strings.Join(uncovered, ", ")))
+	}
+	if len(partial) > 0 {
+		problems = append(problems, fmt.Sprintf("The Enterprise allow list only covers part of %s.", strings.Join(partial, ", ")))
+	}
+
+	if len(problems) == 0 && len(invalid) > 0 {
+		finding.Comment = fmt.Sprintf("Could not parse the allow list values %s.", strings.Join(invalid, ", "))
+		finding.Status = statusUnknown
+		return finding
+	}
+
+	if len(problems) == 0 {
+		finding.Comment = "Every active Organization allow list entry is covered by the Enterprise allow list."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = strings.Join(problems, " ") + " Users and CI connecting from these addresses will be blocked."
+	finding.Status = statusFail
+	finding.Remediation = fmt.Sprintf("Add %s to the Enterprise allow list before the transfer.", strings.Join(append(uncovered, partial...), ", "))
+
+	return finding
+}
+
+func compareIpAllowListInactiveEntries(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	orgEntries := org.GQL.Organization.IpAllowListEntries
+	entEntries := ent.Enterprise.OwnerInfo.IpAllowListEntries
+	finding := Finding{
+		SourceValue:    orgEntries.inactiveValues(),
+		TargetValue:    entEntries.inactiveValues(),
+		EffectiveValue: orgEntries.inactiveValues(),
+	}
+
+	if ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting != "ENABLED" {
+		finding.Comment = "The Enterprise IP allow list is not enabled."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = entEntries.inactiveValues()
+
+	// inactive enterprise entries matter when they are all that would let an organization range in
+	var needed []string
+	seen := make(map[string]bool)
+	for _, coverage := range analyzeIpAllowListCoverage(orgEntries, entEntries) {
+		if !coverage.Entry.IsActive || coverage.Coverage == coverageCovered {
+			continue
+		}
+
+		for _, value := range coverage.InactiveCoveredBy {
+			if !seen[value] {
+				seen[value] = true
+				needed = append(needed, value)
+			}
+		}
+	}
+
+	if len(needed) == 0 {
+		finding.Comment = "No inactive Enterprise allow list entry is needed by the Organization."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The inactive Enterprise allow list entries %s cover addresses the Organization allows today.", strings.Join(needed, ", "))
+	finding.Status = statusFail
+	finding.Remediation = fmt.Sprintf("Activate %s in the Enterprise allow list before the transfer.", strings.Join(needed, ", "))
+
+	return finding
+}
+
+func compareIpAllowListRedundancies(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	orgRedundancies := ipAllowListRedundancies(org.GQL.Organization.IpAllowListEntries)
+	entRedundancies := ipAllowListRedundancies(ent.Enterprise.OwnerInfo.IpAllowListEntries)
+	finding := Finding{
+		SourceValue:    strings.Join(orgRedundancies, ", "),
+		TargetValue:    strings.Join(entRedundancies, ", "),
+		EffectiveValue: strings.Join(entRedundancies, ", "),
+	}
+
+	if len(orgRedundancies) == 0 && len(entRedundancies) == 0 {
+		finding.Comment = "Neither allow list has duplicate or nested entries."
+		finding.Status = statusPass
+		return finding
+	}
+
+	var problems []string
+	if len(orgRedundancies) > 0 {
+		problems = append(problems, fmt.Sprintf("In the Organization allow list, %s.", strings.Join(orgRedundancies, ", ")))
+	}
+	if len(entRedundancies) > 0 {
+		problems = append(problems, fmt.Sprintf("In the Enterprise allow list, %s.", strings.Join(entRedundancies, ", ")))
+	}
+
+	finding.Comment = strings.Join(problems, " ")
+	finding.Status = statusFail
+	finding.Remediation = "Remove the redundant entries so the allow lists are easier to review."
+
+	return finding
+}
+
+func compareIpAllowListForInstalledApps(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	entSetting := ent.Enterprise.OwnerInfo.IpAllowListForInstalledAppsEnabledSetting
+	orgSetting := org.GQL.Organization.IpAllowListForInstalledAppsEnabledSetting
+	finding := Finding{
+		SourceValue:    orgSetting,
+		TargetValue:    entSetting,
+		EffectiveValue: orgSetting,
+	}
+
+	if ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting != "ENABLED" {
+		finding.Comment = "The Enterprise IP allow list is not enabled. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = entSetting
+
+	// without an allow list of its own, the apps installed on the Organization have not been restricted so far
+	if entSetting != "ENABLED" && org.GQL.Organization.IpAllowListEnabledSetting != "ENABLED" {
+		finding.Comment = "The Organization has no IP allow list today, and the Enterprise does not add the IP allow lists of installed GitHub Apps. Apps installed on the Organization will be blocked from addresses outside the Enterprise allow list."
+		finding.Status = statusFail
+		finding.Remediation = "Enable the IP allow list for installed GitHub Apps in the Enterprise, or add the addresses the apps connect from to the Enterprise allow list."
+		return finding
+	}
+
+	if entSetting == orgSetting {
+		finding.Comment = "The Enterprise and the Organization use the same IP allow list configuration for installed GitHub Apps."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if entSetting == "ENABLED" {
+		finding.Comment = "The Enterprise adds the IP allow lists of installed GitHub Apps to its allow list. Apps installed on the Organization will be allowed from their own addresses."
+		finding.Remediation = "Review the IP allow lists of the GitHub Apps installed on the Organization."
+	} else {
+		finding.Comment = "The Enterprise does not add the IP allow lists of installed GitHub Apps. Apps installed on the Organization may lose access from addresses outside the Enterprise allow list."
+		finding.Remediation = "Add the addresses used by installed GitHub Apps to the Enterprise allow list before the transfer."
+	}
+	finding.Status = statusFail
+
+	return finding
+}
+
+func compareNotificationDeliveryRestriction(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.NotificationDeliveryRestrictionEnabledSetting
+	orgSetting := org.GQL.Organization.NotificationDeliveryRestrictionEnabledSetting
+	finding := Finding{
+		SourceValue:    orgSetting,
+		TargetValue:    setting,
+		EffectiveValue: orgSetting,
+	}
+
+	if setting != "ENABLED" {
+		finding.Comment = "The Enterprise does not restrict email notification delivery. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = setting
+
+	if orgSetting == "ENABLED" {
+		finding.Comment = "The Enterprise and the Organization both restrict email notifications to verified domains. Only Enterprise verified domains will be accepted."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = "The Enterprise restricts email notifications to its verified domains. Members without an email address on those domains will stop receiving notifications."
+	finding.Status = statusFail
+	finding.Remediation = "Ask members to add and verify an email address on an Enterprise verified domain before the transfer."
+
+	return finding
+}
+
+func compareTwoFactorAuthentication(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting
+	orgValue := strconv.FormatBool(org.GQL.Organization.RequiresTwoFactorAuthentication)
+	finding := Finding{
+		SourceValue:    orgValue,
+		TargetValue:    setting,
+		EffectiveValue: effectiveValue(setting, orgValue, strconv.FormatBool(setting == "ENABLED")),
+	}
+
+	if noEnterprisePolicy(setting) {
+		finding.Comment = "There is no Enterprise policy."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if setting == "ENABLED" {
+		if org.GQL.Organization.RequiresTwoFactorAuthentication {
+			finding.Comment = "The Enterprise and the Organization both have Two Factor Authentication enabled."
+			finding.Status = statusPass
+		}
+
+		if !org.GQL.Organization.RequiresTwoFactorAuthentication {
+			finding.Comment = "The Enterprise two factor authentication setting will apply to the Organization. Members who do not have two factor authentication enabled will lose access to the Organization, outside collaborators will be removed. See the users without two factor authentication finding for who is affected."
+			finding.Status = statusFail
+			finding.Remediation = "Ask members and outside collaborators without two factor authentication to enable it before the transfer."
+		}
+
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("Unrecognized Enterprise setting %q.", setting)
+	finding.Status = statusUnknown
+
+	return finding
+}
+
+func compareSamlIdentityProvider(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	entProvider := ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id
+	orgProvider := org.GQL.Organization.SamlIdentityProvider.Id
+	finding := Finding{
+		SourceValue:    orgProvider,
+		TargetValue:    entProvider,
+		EffectiveValue: orgProvider,
+	}
+
+	if entProvider == "" {
+		finding.Comment = "SAML Single Sign On is not enabled at the Enterprise level."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = entProvider
+
+	if orgProvider == "" {
+		finding.Comment = "SAML Single Sign On is enabled at the Enterprise level, but not at the Organization level."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if orgProvider != entProvider {
+		finding.Comment = "SAML Single Sign On is enabled at the Enterprise level and the Organization level. The Enterprise SAML Single Sign On provider will apply to the Organization."
+		finding.Status = statusFail
+		finding.Remediation = "Make sure every member has an identity in the Enterprise identity provider before the transfer."
+		return finding
+	}
+
+	finding.Comment = "The Enterprise and the Organization use the same SAML Single Sign On provider."
+	finding.Status = statusPass
+
+	return finding
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CustomRule is a rule written as an expression in a rules file, for example:
+//
+//	rules:
+//	  - id: base-permission-read
+//	    policy: Base Repository Permission
+//	    category: repository
+//	    severity: high
+//	    expression: org.rest.default_repository_permission in ["none", "read"]
+//	    comment: Members should not get write access to every repository.
+//	    remediation: Set the base repository permission to read.
+type CustomRule struct {
+	ID          string   `yaml:"id"`
+	Policy      string   `yaml:"policy"`
+	Category    string   `yaml:"category"`
+	Severity    Severity `yaml:"severity"`
+	Expression  string   `yaml:"expression"`
+	Comment     string   `yaml:"comment"`
+	Remediation string   `yaml:"remediation"`
+}
+
+// loadCustomRules reads a rules file and parses every expression in it.
+func loadCustomRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read rules %s: %w", path, err)
+	}
+
+	var file struct {
+		Rules []CustomRule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("could not parse rules %s: %w", path, err)
+	}
+
+	rules := make([]Rule, 0, len(file.Rules))
+
+	for i, custom := range file.Rules {
+		if custom.ID == "" {
+			return nil, fmt.Errorf("rule %d in %s has no id", i+1, path)
+		}
+
+		rule, err := custom.rule()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s in %s: %w", custom.ID, path, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// rule turns a custom rule into a Rule that can be registered alongside the built-in comparisons.
+func (c CustomRule) rule() (Rule, error) {
+	expression, err := parseExpression(c.Expression)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	if c.Policy == "" {
+		c.Policy = c.ID
+	}
+	if c.Category == "" {
+		c.Category = "custom"
+	}
+	if c.Severity == 0 {
+		c.Severity = SeverityMedium
+	}
+
+	return Rule{
+		ID:       c.ID,
+		Policy:   c.Policy,
+		Category: c.Category,
+		Severity: c.Severity,
+		Inputs:   expression.Identifiers,
+		Evaluate: func(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+			return c.evaluate(expression, policyExprEnv(org, ent))
+		},
+	}, nil
+}
+
+func (c CustomRule) evaluate(expression *Expression, env exprEnv) Finding {
+	var sourceValues, targetValues []string
+	for _, identifier := range expression.Identifiers {
+		value, ok := env[identifier]
+		if !ok {
+			continue
+		}
+
+		formatted := fmt.Sprintf("%s=%v", identifier, value)
+		if strings.HasPrefix(identifier, "ent.") {
+			targetValues = append(targetValues, formatted)
+		} else {
+			sourceValues = append(sourceValues, formatted)
+		}
+	}
+
+	finding := Finding{
+		SourceValue: strings.Join(sourceValues, ", "),
+		TargetValue: strings.Join(targetValues, ", "),
+	}
+
+	passed, err := expression.Evaluate(env)
+
+	switch {
+	case err != nil:
+		finding.Comment = fmt.Sprintf("Could not evaluate %s: %s.", expression.Source, err)
+		finding.Status = statusUnknown
+	case passed:
+		finding.Comment = fmt.Sprintf("%s holds.", expression.Source)
+		finding.Status = statusPass
+	default:
+		finding.Comment = c.Comment
+		if finding.Comment == "" {
+			finding.Comment = fmt.Sprintf("%s does not hold.", expression.Source)
+		}
+		finding.Remediation = c.Remediation
+		finding.Status = statusFail
+	}
+
+	return finding
+}
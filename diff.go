@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// settingMetadata is the category and severity used for a setting when two sets of settings are diffed.
+type settingMetadata struct {
+	Category string
+	Severity Severity
+}
+
+// organizationSettingMetadata describes the organizationSettings keys. Keys that are missing default
+// to the "organization" category with a low severity.
+var organizationSettingMetadata = map[string]settingMetadata{
+	"HasOrganizationProjects":                               {"organization", SeverityLow},
+	"HasRepositoryProjects":                                 {"repository", SeverityLow},
+	"DefaultRepositoryPermission":                           {"repository", SeverityHigh},
+	"MembersCanCreateRepositories":                          {"repository", SeverityMedium},
+	"TwoFactorRequirementEnabled":                           {"account", SeverityHigh},
+	"MembersAllowedRepositoryCreationType":                  {"repository", SeverityMedium},
+	"MembersCanCreatePublicRepositories":                    {"repository", SeverityMedium},
+	"MembersCanCreatePrivateRepositories":                   {"repository", SeverityLow},
+	"MembersCanCreateInternalRepositories":                  {"repository", SeverityLow},
+	"MembersCanCreatePages":                                 {"repository", SeverityLow},
+	"MembersCanForkPrivateRepositoriesREST":                 {"repository", SeverityMedium},
+	"IpAllowListEnabledSetting":                             {"network", SeverityHigh},
+	"IpAllowListEntries":                                    {"network", SeverityHigh},
+	"IpAllowListForInstalledAppsEnabledSetting":             {"network", SeverityMedium},
+	"MembersCanForkPrivateRepositories":                     {"repository", SeverityMedium},
+	"NotificationDeliveryRestrictionEnabledSetting":         {"member", SeverityMedium},
+	"RequiresTwoFactorAuthentication":                       {"account", SeverityHigh},
+	"SamlIdentityProviderIssuer":                            {"account", SeverityHigh},
+	"SamlIdentityProviderSsoUrl":                            {"account", SeverityMedium},
+	"SamlIdentityProviderCertificate":                       {"account", SeverityMedium},
+	"AdvancedSecurityEnabledForNewRepositories":             {"security", SeverityMedium},
+	"DependabotAlertsEnabledForNewRepositories":             {"security", SeverityMedium},
+	"DependabotSecurityUpdatesEnabledForNewRepositories":    {"security", SeverityLow},
+	"DependencyGraphEnabledForNewRepositories":              {"security", SeverityLow},
+	"SecretScanningEnabledForNewRepositories":               {"security", SeverityMedium},
+	"SecretScanningPushProtectionEnabledForNewRepositories": {"security", SeverityMedium},
+}
+
+// enterpriseSettingMetadata describes the enterpriseSettings keys.
+var enterpriseSettingMetadata = map[string]settingMetadata{
+	"AllowPrivateRepositoryForkingSetting":            {"repository", SeverityMedium},
+	"AllowPrivateRepositoryForkingSettingPolicyValue": {"repository", SeverityLow},
+	"DefaultRepositoryPermissionSetting":              {"repository", SeverityHigh},
+	"IpAllowListEnabledSetting":                       {"network", SeverityHigh},
+	"IpAllowListEntries":                              {"network", SeverityHigh},
+	"IpAllowListForInstalledAppsEnabledSetting":       {"network", SeverityMedium},
+	"MembersCanChangeRepositoryVisibilitySetting":     {"repository", SeverityLow},
+	"MembersCanCreateRepositoriesSetting":             {"repository", SeverityMedium},
+	"MembersCanDeleteIssuesSetting":                   {"repository", SeverityLow},
+	"MembersCanDeleteRepositoriesSetting":             {"repository", SeverityMedium},
+	"MembersCanInviteCollaboratorsSetting":            {"member", SeverityMedium},
+	"MembersCanMakePurchasesSetting":                  {"billing", SeverityLow},
+	"MembersCanUpdateProtectedBranchesSetting":        {"repository", SeverityMedium},
+	"MembersCanViewDependencyInsightsSetting":         {"member", SeverityLow},
+	"NotificationDeliveryRestrictionEnabledSetting":   {"member", SeverityMedium},
+	"OrganizationProjectsSetting":                     {"organization", SeverityLow},
+	"RepositoryProjectsSetting":                       {"repository", SeverityLow},
+	"SamlIdentityProviderIssuer":                      {"account", SeverityBlocker},
+	"SamlIdentityProviderSsoUrl":                      {"account", SeverityMedium},
+	"SamlIdentityProviderCertificate":                 {"account", SeverityMedium},
+	"TeamDiscussionsSetting":                          {"organization", SeverityLow},
+	"TwoFactorRequiredSetting":                        {"account", SeverityBlocker},
+}
+
+// diffSettings compares two sets of named settings key by key and returns one finding per key. An
+// empty value is a setting that was not returned, so it is reported as unknown rather than compared.
+func diffSettings(sourceName string, targetName string, source []PolicySetting, target []PolicySetting, metadata map[string]settingMetadata) []Finding {
+	sourceValues := make(map[string]string)
+	targetValues := make(map[string]string)
+	var keys []string
+
+	for _, setting := range source {
+		sourceValues[setting.Key] = setting.Value
+		keys = append(keys, setting.Key)
+	}
+
+	for _, setting := range target {
+		if _, ok := sourceValues[setting.Key]; !ok {
+			if _, ok := targetValues[setting.Key]; !ok {
+				keys = append(keys, setting.Key)
+			}
+		}
+		targetValues[setting.Key] = setting.Value
+	}
+
+	findings := make([]Finding, 0, len(keys))
+
+	for _, key := range keys {
+		meta, ok := metadata[key]
+		if !ok {
+			meta = settingMetadata{"organization", SeverityLow}
+		}
+
+		finding := Finding{
+			RuleID:      key,
+			Policy:      key,
+			Category:    meta.Category,
+			Severity:    SeverityInfo,
+			SourceValue: sourceValues[key],
+			TargetValue: targetValues[key],
+		}
+
+		switch {
+		case finding.SourceValue == "" || finding.TargetValue == "":
+			var missing []string
+			if finding.SourceValue == "" {
+				missing = append(missing, sourceName)
+			}
+			if finding.TargetValue == "" {
+				missing = append(missing, targetName)
+			}
+			finding.Comment = fmt.Sprintf("%s did not return %s.", strings.Join(missing, " and "), key)
+			finding.Remediation = fmt.Sprintf("Run the comparison as an owner of %s and %s to compare %s.", sourceName, targetName, key)
+			finding.Severity = meta.Severity
+			finding.Status = statusUnknown
+		case finding.SourceValue == finding.TargetValue:
+			finding.Comment = fmt.Sprintf("%s and %s both have %s set to %q.", sourceName, targetName, key, finding.SourceValue)
+			finding.Status = statusPass
+		default:
+			finding.Comment = fmt.Sprintf("%s has %s set to %q, %s has %q.", sourceName, key, finding.SourceValue, targetName, finding.TargetValue)
+			finding.Remediation = fmt.Sprintf("Decide whether %s should match %s before the transfer.", key, targetName)
+			finding.Severity = meta.Severity
+			finding.Status = statusFail
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+// withSamlProviderSettings replaces the SamlIdentityProvider node ID of a set of settings with the provider
+// configuration. Two owners never share a node ID, so only their configurations can be compared.
+func withSamlProviderSettings(settings []PolicySetting, provider SamlIdentityProvider) []PolicySetting {
+	var replaced []PolicySetting
+	for _, setting := range settings {
+		if setting.Key == "SamlIdentityProvider" {
+			replaced = append(replaced, samlProviderDiffSettings(provider)...)
+			continue
+		}
+		replaced = append(replaced, setting)
+	}
+
+	return replaced
+}
+
+// compareOrganizations diffs every organization setting of source against a reference organization.
+func compareOrganizations(sourceName string, source *OrganizationPolicies, targetName string, target *OrganizationPolicies) []Finding {
+	fmt.Println("Comparing Organization Policies")
+
+	sourceSettings := withSamlProviderSettings(organizationSettings(*source), source.GQL.Organization.SamlIdentityProvider)
+	targetSettings := withSamlProviderSettings(organizationSettings(*target), target.GQL.Organization.SamlIdentityProvider)
+
+	return diffSettings(sourceName, targetName, sourceSettings, targetSettings, organizationSettingMetadata)
+}
+
+// compareEnterprises diffs every owner info setting of a source enterprise against a target enterprise,
+// describing which enforced policies appear, disappear or change for the organizations that move.
+func compareEnterprises(sourceName string, source *EnterprisePolicies, targetName string, target *EnterprisePolicies) []Finding {
+	fmt.Println("Comparing Enterprise Policies")
+
+	sourceSettings := withSamlProviderSettings(enterpriseSettings(*source), source.Enterprise.OwnerInfo.SamlIdentityProvider)
+	targetSettings := withSamlProviderSettings(enterpriseSettings(*target), target.Enterprise.OwnerInfo.SamlIdentityProvider)
+
+	findings := diffSettings(sourceName, targetName, sourceSettings, targetSettings, enterpriseSettingMetadata)
+
+	for i, finding := range findings {
+		if finding.Status != statusFail {
+			continue
+		}
+
+		findings[i].EffectiveValue = finding.TargetValue
+
+		switch {
+		case noEnterprisePolicy(finding.SourceValue):
+			findings[i].Comment = fmt.Sprintf("%s enforces %s as %q. Moving organizations will start following this policy.", targetName, finding.Policy, finding.TargetValue)
+		case noEnterprisePolicy(finding.TargetValue):
+			findings[i].Comment = fmt.Sprintf("%s does not enforce %s. Moving organizations will keep their own setting instead of %q.", targetName, finding.Policy, finding.SourceValue)
+			findings[i].Severity = SeverityLow
+		default:
+			findings[i].Comment = fmt.Sprintf("%s is enforced as %q in %s and as %q in %s. Moving organizations will switch to %q.", finding.Policy, finding.SourceValue, sourceName, finding.TargetValue, targetName, finding.TargetValue)
+		}
+	}
+
+	return findings
+}
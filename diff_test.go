@@ -0,0 +1,108 @@
+package main
+
+import "testing"
+
+func TestCompareOrganizations(t *testing.T) {
+	source := new(OrganizationPolicies)
+	source.REST.Default_repository_permission = "write"
+	source.GQL.Organization.RequiresTwoFactorAuthentication = true
+	source.GQL.Organization.SamlIdentityProvider = SamlIdentityProvider{Id: "O_kgDOA", Issuer: "https://sts.example.com", SsoUrl: "https://sts.example.com/saml"}
+
+	reference := new(OrganizationPolicies)
+	reference.REST.Default_repository_permission = "read"
+	reference.GQL.Organization.RequiresTwoFactorAuthentication = true
+	reference.GQL.Organization.SamlIdentityProvider = SamlIdentityProvider{Id: "O_kgDOB", Issuer: "https://sts.example.com", SsoUrl: "https://sts.example.com/sso"}
+
+	findings := compareOrganizations("octodemo", source, "octocat", reference)
+
+	if len(findings) != len(organizationSettings(*source))+2 {
+		t.Fatalf("expected one finding per setting, got %d", len(findings))
+	}
+
+	byID := make(map[string]Finding)
+	for _, finding := range findings {
+		byID[finding.RuleID] = finding
+	}
+
+	permission := byID["DefaultRepositoryPermission"]
+	if permission.Status != statusFail || permission.Severity != SeverityHigh {
+		t.Errorf("unexpected DefaultRepositoryPermission finding %+v", permission)
+	}
+	if permission.SourceValue != "write" || permission.TargetValue != "read" {
+		t.Errorf("unexpected DefaultRepositoryPermission values %q, %q", permission.SourceValue, permission.TargetValue)
+	}
+
+	twoFactor := byID["RequiresTwoFactorAuthentication"]
+	if twoFactor.Status != statusPass || twoFactor.Severity != SeverityInfo {
+		t.Errorf("unexpected RequiresTwoFactorAuthentication finding %+v", twoFactor)
+	}
+
+	if _, ok := byID["SamlIdentityProvider"]; ok {
+		t.Error("expected the SAML identity provider node IDs not to be compared")
+	}
+	if got := byID["SamlIdentityProviderIssuer"]; got.Status != statusPass {
+		t.Errorf("expected the same issuer to pass, got %+v", got)
+	}
+	if got := byID["SamlIdentityProviderSsoUrl"]; got.Status != statusFail {
+		t.Errorf("expected different SSO URLs to fail, got %+v", got)
+	}
+}
+
+func TestDiffSettingsIncludesTargetOnlyKeys(t *testing.T) {
+	source := []PolicySetting{{"A", "1"}, {"C", ""}}
+	target := []PolicySetting{{"A", "1"}, {"B", "2"}, {"C", ""}}
+
+	findings := diffSettings("source", "target", source, target, nil)
+
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d", len(findings))
+	}
+	if findings[2].RuleID != "B" || findings[2].SourceValue != "" || findings[2].Status != statusUnknown || findings[2].Comment != "source did not return B." {
+		t.Errorf("unexpected finding for a target-only key %+v", findings[2])
+	}
+	if findings[1].RuleID != "C" || findings[1].Status != statusUnknown || findings[1].Comment != "source and target did not return C." {
+		t.Errorf("expected a setting missing on both sides to be unknown, got %+v", findings[1])
+	}
+}
+
+func TestCompareEnterprises(t *testing.T) {
+	source := new(EnterprisePolicies)
+	source.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "NO_POLICY"
+	source.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting = "READ"
+	source.Enterprise.OwnerInfo.TeamDiscussionsSetting = "DISABLED"
+	source.Enterprise.OwnerInfo.MembersCanDeleteIssuesSetting = "DISABLED"
+	source.Enterprise.OwnerInfo.SamlIdentityProvider = SamlIdentityProvider{Id: "E_kgDOA", Issuer: "https://sts.example.com", SsoUrl: "https://sts.example.com/saml"}
+	source.Enterprise.OwnerInfo.IpAllowListEntries.Nodes = []IpAllowListEntry{{AllowListValue: "198.51.100.7"}, {AllowListValue: "192.0.2.0/24"}}
+
+	target := new(EnterprisePolicies)
+	target.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+	target.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting = "WRITE"
+	target.Enterprise.OwnerInfo.TeamDiscussionsSetting = "NO_POLICY"
+	target.Enterprise.OwnerInfo.MembersCanDeleteIssuesSetting = "DISABLED"
+	target.Enterprise.OwnerInfo.SamlIdentityProvider = SamlIdentityProvider{Id: "E_kgDOB", Issuer: "https://sts.example.com", SsoUrl: "https://sts.example.com/saml"}
+	target.Enterprise.OwnerInfo.IpAllowListEntries.Nodes = []IpAllowListEntry{{AllowListValue: "192.0.2.0/24"}, {AllowListValue: "198.51.100.7"}}
+
+	byID := make(map[string]Finding)
+	for _, finding := range compareEnterprises("acquired", source, "parent", target) {
+		byID[finding.RuleID] = finding
+	}
+
+	if got := byID["TwoFactorRequiredSetting"]; got.Status != statusFail || got.Severity != SeverityBlocker || got.EffectiveValue != "ENABLED" {
+		t.Errorf("expected an appearing 2FA policy to be a blocker, got %+v", got)
+	}
+	if got := byID["TeamDiscussionsSetting"]; got.Status != statusFail || got.Severity != SeverityLow {
+		t.Errorf("expected a disappearing policy to be low severity, got %+v", got)
+	}
+	if got := byID["DefaultRepositoryPermissionSetting"]; got.Severity != SeverityHigh {
+		t.Errorf("expected a changed base permission to be high severity, got %+v", got)
+	}
+	if got := byID["MembersCanDeleteIssuesSetting"]; got.Status != statusPass {
+		t.Errorf("expected identical settings to pass, got %+v", got)
+	}
+	if got := byID["SamlIdentityProviderIssuer"]; got.Status != statusPass {
+		t.Errorf("expected identity providers with the same issuer to pass, got %+v", got)
+	}
+	if got := byID["IpAllowListEntries"]; got.Status != statusPass {
+		t.Errorf("expected the same allow list in another order to pass, got %+v", got)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EffectiveSetting is an organization setting as it is today and as it will be once the
+// organization joins the enterprise. EnforcedBy names the enterprise setting that decides the
+// effective value, and is empty when the organization keeps its own value.
+type EffectiveSetting struct {
+	Key        string
+	Current    string
+	Effective  string
+	EnforcedBy string
+}
+
+// enterpriseOverride returns the value an enterprise enforces for an organization setting, and
+// whether it enforces one at all.
+type enterpriseOverride struct {
+	key        string
+	enforcedBy string
+	enforce    func(ent *EnterprisePolicies) (string, bool)
+}
+
+// enabledOverride enforces "true" or "false" when an ENABLED / DISABLED / NO_POLICY setting has a policy.
+func enabledOverride(value func(ent *EnterprisePolicies) string) func(ent *EnterprisePolicies) (string, bool) {
+	return func(ent *EnterprisePolicies) (string, bool) {
+		setting := value(ent)
+		if noEnterprisePolicy(setting) {
+			return "", false
+		}
+
+		return strconv.FormatBool(setting == "ENABLED"), true
+	}
+}
+
+// settingOverride enforces the enterprise value as is when it has a policy.
+func settingOverride(value func(ent *EnterprisePolicies) string) func(ent *EnterprisePolicies) (string, bool) {
+	return func(ent *EnterprisePolicies) (string, bool) {
+		setting := value(ent)
+		if noEnterprisePolicy(setting) {
+			return "", false
+		}
+
+		return setting, true
+	}
+}
+
+// ipAllowListOverride enforces an IP allow list value once the enterprise allow list is enabled.
+func ipAllowListOverride(value func(ent *EnterprisePolicies) string) func(ent *EnterprisePolicies) (string, bool) {
+	return func(ent *EnterprisePolicies) (string, bool) {
+		if ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting != "ENABLED" {
+			return "", false
+		}
+
+		return value(ent), true
+	}
+}
+
+// repositoryCreationOverride enforces whether members may create repositories of a visibility.
+func repositoryCreationOverride(visibility string) func(ent *EnterprisePolicies) (string, bool) {
+	return func(ent *EnterprisePolicies) (string, bool) {
+		setting := ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting
+		if noEnterprisePolicy(setting) {
+			return "", false
+		}
+
+		return strconv.FormatBool(enterpriseRepositoryCreation(setting)[visibility]), true
+	}
+}
+
+func allowPrivateRepositoryForking(ent *EnterprisePolicies) string {
+	return ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting
+}
+
+func twoFactorRequired(ent *EnterprisePolicies) string {
+	return ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting
+}
+
+// organizationOverrides maps the organizationSettings keys to the enterprise settings that override them.
+var organizationOverrides = []enterpriseOverride{
+	{"HasOrganizationProjects", "OrganizationProjectsSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.OrganizationProjectsSetting
+	})},
+	{"HasRepositoryProjects", "RepositoryProjectsSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.RepositoryProjectsSetting
+	})},
+	{"DefaultRepositoryPermission", "DefaultRepositoryPermissionSetting", func(ent *EnterprisePolicies) (string, bool) {
+		setting := ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting
+		return strings.ToLower(setting), !noEnterprisePolicy(setting)
+	}},
+	{"MembersCanCreateRepositories", "MembersCanCreateRepositoriesSetting", func(ent *EnterprisePolicies) (string, bool) {
+		setting := ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting
+		return strconv.FormatBool(setting != "DISABLED"), !noEnterprisePolicy(setting)
+	}},
+	{"TwoFactorRequirementEnabled", "TwoFactorRequiredSetting", enabledOverride(twoFactorRequired)},
+	{"MembersAllowedRepositoryCreationType", "MembersCanCreateRepositoriesSetting", func(ent *EnterprisePolicies) (string, bool) {
+		setting := ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting
+		if setting == "DISABLED" {
+			return "none", true
+		}
+		return strings.ToLower(setting), !noEnterprisePolicy(setting)
+	}},
+	{"MembersCanCreatePublicRepositories", "MembersCanCreateRepositoriesSetting", repositoryCreationOverride("public")},
+	{"MembersCanCreatePrivateRepositories", "MembersCanCreateRepositoriesSetting", repositoryCreationOverride("private")},
+	{"MembersCanCreateInternalRepositories", "MembersCanCreateRepositoriesSetting", repositoryCreationOverride("internal")},
+	{"MembersCanForkPrivateRepositoriesREST", "AllowPrivateRepositoryForkingSetting", enabledOverride(allowPrivateRepositoryForking)},
+	{"IpAllowListEnabledSetting", "IpAllowListEnabledSetting", ipAllowListOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting
+	})},
+	{"IpAllowListEntries", "IpAllowListEntries", ipAllowListOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.IpAllowListEntries.values()
+	})},
+	{"IpAllowListForInstalledAppsEnabledSetting", "IpAllowListForInstalledAppsEnabledSetting", ipAllowListOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.IpAllowListForInstalledAppsEnabledSetting
+	})},
+	{"MembersCanForkPrivateRepositories", "AllowPrivateRepositoryForkingSetting", enabledOverride(allowPrivateRepositoryForking)},
+	{"NotificationDeliveryRestrictionEnabledSetting", "NotificationDeliveryRestrictionEnabledSetting", func(ent *EnterprisePolicies) (string, bool) {
+		setting := ent.Enterprise.OwnerInfo.NotificationDeliveryRestrictionEnabledSetting
+		return setting, setting == "ENABLED"
+	}},
+	{"RequiresTwoFactorAuthentication", "TwoFactorRequiredSetting", enabledOverride(twoFactorRequired)},
+	{"SamlIdentityProvider", "SamlIdentityProvider", func(ent *EnterprisePolicies) (string, bool) {
+		id := ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id
+		return id, id != ""
+	}},
+}
+
+// enterpriseOnlyOverrides are enterprise settings with no organization value in organizationSettings.
+// They still become part of the organization's effective settings after the transfer.
+var enterpriseOnlyOverrides = []enterpriseOverride{
+	{"MembersCanChangeRepositoryVisibility", "MembersCanChangeRepositoryVisibilitySetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanChangeRepositoryVisibilitySetting
+	})},
+	{"MembersCanDeleteIssues", "MembersCanDeleteIssuesSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanDeleteIssuesSetting
+	})},
+	{"MembersCanDeleteRepositories", "MembersCanDeleteRepositoriesSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanDeleteRepositoriesSetting
+	})},
+	{"MembersCanInviteCollaborators", "MembersCanInviteCollaboratorsSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting
+	})},
+	{"MembersCanMakePurchases", "MembersCanMakePurchasesSetting", settingOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanMakePurchasesSetting
+	})},
+	{"MembersCanUpdateProtectedBranches", "MembersCanUpdateProtectedBranchesSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanUpdateProtectedBranchesSetting
+	})},
+	{"MembersCanViewDependencyInsights", "MembersCanViewDependencyInsightsSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.MembersCanViewDependencyInsightsSetting
+	})},
+	{"TeamDiscussions", "TeamDiscussionsSetting", enabledOverride(func(ent *EnterprisePolicies) string {
+		return ent.Enterprise.OwnerInfo.TeamDiscussionsSetting
+	})},
+}
+
+// resolveEffectivePolicies predicts every setting the organization will have once it joins the
+// enterprise. Settings the enterprise leaves to its organizations keep their current value.
+func resolveEffectivePolicies(org *OrganizationPolicies, ent *EnterprisePolicies) []EffectiveSetting {
+	overrides := make(map[string]enterpriseOverride)
+	for _, override := range organizationOverrides {
+		overrides[override.key] = override
+	}
+
+	var settings []EffectiveSetting
+
+	for _, setting := range organizationSettings(*org) {
+		effective := EffectiveSetting{
+			Key:       setting.Key,
+			Current:   setting.Value,
+			Effective: setting.Value,
+		}
+
+		if override, ok := overrides[setting.Key]; ok {
+			if value, enforced := override.enforce(ent); enforced {
+				effective.Effective = value
+				effective.EnforcedBy = "enterprise." + override.enforcedBy
+			}
+		}
+
+		settings = append(settings, effective)
+	}
+
+	for _, override := range enterpriseOnlyOverrides {
+		if value, enforced := override.enforce(ent); enforced {
+			settings = append(settings, EffectiveSetting{
+				Key:        override.key,
+				Effective:  value,
+				EnforcedBy: "enterprise." + override.enforcedBy,
+			})
+		}
+	}
+
+	return settings
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestResolveEffectivePolicies(t *testing.T) {
+	org := new(OrganizationPolicies)
+	enabled, disabled := true, false
+	org.REST.Default_repository_permission = "write"
+	org.REST.Members_can_create_public_repositories = &enabled
+	org.REST.Members_can_create_private_repositories = &disabled
+	org.REST.Members_can_create_pages = &enabled
+	org.GQL.Organization.SamlIdentityProvider.Id = "org-idp"
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting = "READ"
+	ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting = "PRIVATE"
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+	ent.Enterprise.OwnerInfo.MembersCanDeleteIssuesSetting = "DISABLED"
+	ent.Enterprise.OwnerInfo.TeamDiscussionsSetting = "NO_POLICY"
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id = "ent-idp"
+
+	settings := make(map[string]EffectiveSetting)
+	for _, setting := range resolveEffectivePolicies(org, ent) {
+		settings[setting.Key] = setting
+	}
+
+	tests := []struct {
+		key        string
+		current    string
+		effective  string
+		enforcedBy string
+	}{
+		{"DefaultRepositoryPermission", "write", "read", "enterprise.DefaultRepositoryPermissionSetting"},
+		{"MembersCanCreatePublicRepositories", "true", "false", "enterprise.MembersCanCreateRepositoriesSetting"},
+		{"MembersCanCreatePrivateRepositories", "false", "true", "enterprise.MembersCanCreateRepositoriesSetting"},
+		{"MembersCanCreatePages", "true", "true", ""},
+		{"RequiresTwoFactorAuthentication", "false", "true", "enterprise.TwoFactorRequiredSetting"},
+		{"SamlIdentityProvider", "org-idp", "ent-idp", "enterprise.SamlIdentityProvider"},
+		{"MembersCanDeleteIssues", "", "false", "enterprise.MembersCanDeleteIssuesSetting"},
+	}
+
+	for _, tt := range tests {
+		got, ok := settings[tt.key]
+		if !ok {
+			t.Errorf("%s missing from effective settings", tt.key)
+			continue
+		}
+		if got.Current != tt.current || got.Effective != tt.effective || got.EnforcedBy != tt.enforcedBy {
+			t.Errorf("%s = %+v, want current %q effective %q enforced by %q", tt.key, got, tt.current, tt.effective, tt.enforcedBy)
+		}
+	}
+
+	if _, ok := settings["TeamDiscussions"]; ok {
+		t.Error("TeamDiscussions has no Enterprise policy and should not be listed")
+	}
+}
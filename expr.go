@@ -0,0 +1,587 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The rule expression language evaluates boolean checks over collected policy data, for example
+//
+//	org.rest.default_repository_permission in ["none", "read"]
+//	ent.two_factor_required == "ENABLED" implies org.gql.requires_two_factor
+//
+// Identifiers are dotted paths into the policy structs with every field name in snake_case. A
+// trailing "_setting" may be left off, and the few shorter names in exprAliases are accepted as well.
+// Operators, from lowest to highest precedence, are implies, or (||), and (&&), not (!), and the
+// comparisons ==, !=, <, <=, >, >=, in and not in.
+
+// exprEnv holds the values identifiers resolve to.
+type exprEnv map[string]interface{}
+
+type exprNode interface {
+	eval(env exprEnv) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+type identNode struct {
+	name string
+}
+
+type listNode struct {
+	items []exprNode
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+type binaryNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+// Expression is a parsed rule expression together with the identifiers it reads.
+type Expression struct {
+	Source      string
+	Identifiers []string
+	root        exprNode
+}
+
+// parseExpression parses a rule expression.
+func parseExpression(source string) (*Expression, error) {
+	tokens, err := lexExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, seen: make(map[string]bool)}
+
+	root, err := p.parseImplies()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+
+	return &Expression{Source: source, Identifiers: p.identifiers, root: root}, nil
+}
+
+// Evaluate evaluates the expression and returns its boolean result.
+func (e *Expression) Evaluate(env exprEnv) (bool, error) {
+	value, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluates to %v, not a boolean", value)
+	}
+
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lexExpression(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+
+			quoted := string(runes[i : end+1])
+			if r == '\'' {
+				quoted = `"` + strings.ReplaceAll(string(runes[i+1:end]), `"`, `\"`) + `"`
+			}
+
+			text, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+
+			tokens = append(tokens, token{tokenString, text, i})
+			i = end + 1
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, string(runes[start:i]), start})
+		default:
+			op := string(r)
+			if i+1 < len(runes) {
+				switch pair := string(runes[i : i+2]); pair {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					op = pair
+				}
+			}
+			if !strings.Contains("()[],<>!", op) && len(op) == 1 {
+				return nil, fmt.Errorf("unexpected %q at position %d", op, i)
+			}
+
+			tokens = append(tokens, token{tokenOperator, op, i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "end of expression", len(runes)}), nil
+}
+
+type exprParser struct {
+	tokens      []token
+	pos         int
+	identifiers []string
+	seen        map[string]bool
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given operators or keywords.
+func (p *exprParser) accept(texts ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator && t.kind != tokenIdent {
+		return "", false
+	}
+
+	for _, text := range texts {
+		if t.text == text {
+			p.next()
+			return text, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *exprParser) expect(text string) error {
+	if _, ok := p.accept(text); !ok {
+		return fmt.Errorf("expected %q at position %d, found %q", text, p.peek().pos, p.peek().text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseImplies() (exprNode, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.accept("implies"); ok {
+		right, err := p.parseImplies()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{"implies", left, right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("or", "||"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{"or", left, right}
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("and", "&&"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{"and", left, right}
+	}
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if _, ok := p.accept("not", "!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{"not", operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if op, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "in"); ok {
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op, left, right}, nil
+	}
+
+	// "not in" has to look past "not" without consuming it when it starts a new operand.
+	if p.peek().kind == tokenIdent && p.peek().text == "not" && p.tokens[p.pos+1].text == "in" {
+		p.next()
+		p.next()
+
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{"not", &binaryNode{"in", left, right}}, nil
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenString:
+		return &literalNode{t.text}, nil
+	case tokenNumber:
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return &literalNode{number}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		case "and", "or", "not", "implies", "in":
+			return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+		}
+
+		if !p.seen[t.text] {
+			p.seen[t.text] = true
+			p.identifiers = append(p.identifiers, t.text)
+		}
+		return &identNode{t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			node, err := p.parseImplies()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			list := &listNode{}
+			if _, ok := p.accept("]"); ok {
+				return list, nil
+			}
+			for {
+				item, err := p.parsePrimary()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+
+				if _, ok := p.accept("]"); ok {
+					return list, nil
+				}
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+func (n *literalNode) eval(env exprEnv) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *identNode) eval(env exprEnv) (interface{}, error) {
+	value, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s", n.name)
+	}
+	return value, nil
+}
+
+func (n *listNode) eval(env exprEnv) (interface{}, error) {
+	values := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (n *unaryNode) eval(env exprEnv) (interface{}, error) {
+	operand, err := evalBool(n.operand, env)
+	if err != nil {
+		return nil, err
+	}
+	return !operand, nil
+}
+
+func (n *binaryNode) eval(env exprEnv) (interface{}, error) {
+	switch n.op {
+	case "and", "or", "implies":
+		left, err := evalBool(n.left, env)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case n.op == "and" && !left:
+			return false, nil
+		case n.op == "or" && left:
+			return true, nil
+		case n.op == "implies" && !left:
+			return true, nil
+		}
+
+		return evalBool(n.right, env)
+	}
+
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "in":
+		list, ok := right.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("right side of in must be a list, got %v", right)
+		}
+		for _, item := range list {
+			if exprEqual(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	leftNumber, leftOK := left.(float64)
+	rightNumber, rightOK := right.(float64)
+	if !leftOK || !rightOK {
+		return nil, fmt.Errorf("%s needs two numbers, got %v and %v", n.op, left, right)
+	}
+
+	switch n.op {
+	case "<":
+		return leftNumber < rightNumber, nil
+	case "<=":
+		return leftNumber <= rightNumber, nil
+	case ">":
+		return leftNumber > rightNumber, nil
+	default:
+		return leftNumber >= rightNumber, nil
+	}
+}
+
+func evalBool(node exprNode, env exprEnv) (bool, error) {
+	value, err := node.eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean, got %v", value)
+	}
+
+	return result, nil
+}
+
+// exprEqual compares two values. Values of different types are compared by their text, so a
+// boolean field equals the string "true".
+func exprEqual(left interface{}, right interface{}) bool {
+	if reflect.TypeOf(left) == reflect.TypeOf(right) && reflect.TypeOf(left).Comparable() {
+		return left == right
+	}
+
+	return fmt.Sprint(left) == fmt.Sprint(right)
+}
+
+// snakeCase converts a Go field name such as IpAllowListEnabledSetting to ip_allow_list_enabled_setting.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// addExprFields adds every exported field of v to env under prefix, recursing into nested structs.
+func addExprFields(env exprEnv, prefix string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			addExprFields(env, prefix, v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := prefix + "." + snakeCase(field.Name)
+			if field.Anonymous {
+				name = prefix
+			}
+			addExprFields(env, name, v.Field(i))
+		}
+	case reflect.Bool:
+		addExprValue(env, prefix, v.Bool())
+	case reflect.String:
+		addExprValue(env, prefix, v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		addExprValue(env, prefix, float64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		addExprValue(env, prefix, float64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		addExprValue(env, prefix, v.Float())
+	case reflect.Slice:
+		env[prefix+".count"] = float64(v.Len())
+	}
+}
+
+// addExprValue adds a value under name, and under name without a trailing "_setting".
+func addExprValue(env exprEnv, name string, value interface{}) {
+	env[name] = value
+
+	if short := strings.TrimSuffix(name, "_setting"); short != name {
+		if _, ok := env[short]; !ok {
+			env[short] = value
+		}
+	}
+}
+
+// exprAliases are shorter names for identifiers, each resolving to the identifier it maps to.
+var exprAliases = map[string]string{
+	"org.gql.requires_two_factor": "org.gql.requires_two_factor_authentication",
+}
+
+// policyExprEnv exposes organization and enterprise policies to rule expressions as org.gql.*,
+// org.rest.* and ent.*. Either side may be nil, in which case its identifiers are unknown.
+func policyExprEnv(org *OrganizationPolicies, ent *EnterprisePolicies) exprEnv {
+	env := make(exprEnv)
+
+	if org != nil {
+		addExprFields(env, "org.gql", reflect.ValueOf(org.GQL.Organization))
+		addExprFields(env, "org.rest", reflect.ValueOf(org.REST))
+	}
+
+	if ent != nil {
+		addExprFields(env, "ent", reflect.ValueOf(ent.Enterprise.OwnerInfo))
+	}
+
+	for alias, name := range exprAliases {
+		if value, ok := env[name]; ok {
+			env[alias] = value
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	org := new(OrganizationPolicies)
+	org.REST.Default_repository_permission = "read"
+	org.GQL.Organization.RequiresTwoFactorAuthentication = false
+	org.GQL.Organization.IpAllowListEnabledSetting = "ENABLED"
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+
+	env := policyExprEnv(org, ent)
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`org.rest.default_repository_permission in ["none", "read"]`, true},
+		{`org.rest.default_repository_permission not in ["none", "read"]`, false},
+		{`ent.two_factor_required == "ENABLED" implies org.gql.requires_two_factor_authentication`, false},
+		{`ent.two_factor_required_setting == "NO_POLICY" implies org.gql.requires_two_factor_authentication`, true},
+		{`ent.two_factor_required == "ENABLED" implies org.gql.requires_two_factor`, false},
+		{`org.gql.ip_allow_list_enabled_setting == 'ENABLED' and not org.gql.requires_two_factor_authentication`, true},
+		{`!(org.rest.has_organization_projects || org.gql.requires_two_factor_authentication)`, true},
+		{`org.gql.saml_identity_provider.id == ""`, true},
+		{`org.gql.requires_two_factor_authentication == "false"`, true},
+		{`1 < 2 and 2 >= 2`, true},
+	}
+
+	for _, tt := range tests {
+		expression, err := parseExpression(tt.expression)
+		if err != nil {
+			t.Errorf("parse %s: %s", tt.expression, err)
+			continue
+		}
+
+		got, err := expression.Evaluate(env)
+		if err != nil {
+			t.Errorf("evaluate %s: %s", tt.expression, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %t, want %t", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	for _, source := range []string{`org.rest.x ==`, `("a" == "a"`, `org.rest.x = "a"`, `"unterminated`} {
+		if _, err := parseExpression(source); err == nil {
+			t.Errorf("expected %s not to parse", source)
+		}
+	}
+
+	expression, err := parseExpression(`ent.two_factor_required == "ENABLED"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expression.Evaluate(policyExprEnv(new(OrganizationPolicies), nil)); err == nil {
+		t.Error("expected an unknown field error without enterprise policies")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"IpAllowListEnabledSetting": "ip_allow_list_enabled_setting",
+		"Has_organization_projects": "has_organization_projects",
+		"Id":                        "id",
+		"SSOUrl":                    "sso_url",
+	}
+
+	for name, want := range tests {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoadCustomRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	contents := `
+rules:
+  - id: base-permission-read
+    severity: high
+    expression: org.rest.default_repository_permission in ["none", "read"]
+    remediation: Set the base repository permission to read.
+  - id: two-factor
+    expression: ent.two_factor_required == "ENABLED" implies org.gql.requires_two_factor_authentication
+`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := loadCustomRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	org := new(OrganizationPolicies)
+	org.REST.Default_repository_permission = "write"
+
+	permission := evaluateRule(rules[0], org, nil)
+	if permission.Status != statusFail || permission.Severity != SeverityHigh || permission.Category != "custom" {
+		t.Errorf("unexpected finding %+v", permission)
+	}
+	if permission.SourceValue != "org.rest.default_repository_permission=write" {
+		t.Errorf("unexpected source value %q", permission.SourceValue)
+	}
+
+	if twoFactor := evaluateRule(rules[1], org, nil); twoFactor.Status != statusUnknown {
+		t.Errorf("expected an unknown status without enterprise policies, got %+v", twoFactor)
+	}
+}
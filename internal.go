@@ -0,0 +1,318 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// sensitiveKeywords are the words in repository topics and custom property values that mark a
+// repository as too sensitive to be readable by every enterprise member.
+var sensitiveKeywords = []string{"classified", "confidential", "gdpr", "hipaa", "pci", "phi", "pii", "restricted", "secret", "secrets", "sensitive", "sox"}
+
+// InternalRepository is an internal repository of an organization and the custom property values set on it.
+type InternalRepository struct {
+	Repository Repository
+	Properties map[string]string
+}
+
+// InternalRepositories are the internal repositories of an organization and the member counts that
+// decide who can read them. EnterpriseMembers is 0 when no enterprise is given.
+type InternalRepositories struct {
+	Repositories        []InternalRepository
+	OrganizationMembers int
+	EnterpriseMembers   int
+}
+
+type organizationMemberCount struct {
+	Organization struct {
+		MembersWithRole struct {
+			TotalCount int
+		}
+	} `graphql:"organization(login: $login)"`
+}
+
+type enterpriseMemberCount struct {
+	Enterprise struct {
+		Members struct {
+			TotalCount int
+		}
+	} `graphql:"enterprise(slug: $slug)"`
+}
+
+// getOrganizationInternalRepositories lists the internal repositories of an organization with their
+// custom property values, and counts the members of the organization and of the enterprise.
+func getOrganizationInternalRepositories(org string, repositories []Repository, ent string) (*InternalRepositories, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	properties, err := getRepositoryPropertyValues(client, org)
+	if err != nil {
+		return nil, err
+	}
+
+	internal := new(InternalRepositories)
+	for _, repository := range repositories {
+		if repository.Visibility == "internal" {
+			internal.Repositories = append(internal.Repositories, InternalRepository{
+				Repository: repository,
+				Properties: properties[repository.Full_name],
+			})
+		}
+	}
+
+	gqlClient, err := gh.GQLClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	orgCount := new(organizationMemberCount)
+	if err := gqlClient.Query("OrganizationMemberCount", orgCount, map[string]interface{}{"login": graphql.String(org)}); err != nil {
+		return nil, err
+	}
+	internal.OrganizationMembers = orgCount.Organization.MembersWithRole.TotalCount
+
+	if ent != "" {
+		entCount := new(enterpriseMemberCount)
+		if err := gqlClient.Query("EnterpriseMemberCount", entCount, map[string]interface{}{"slug": graphql.String(ent)}); err != nil {
+			return nil, err
+		}
+		internal.EnterpriseMembers = entCount.Enterprise.Members.TotalCount
+	}
+
+	return internal, nil
+}
+
+// getRepositoryPropertyValues returns the custom property values of every repository of an organization,
+// by repository full name. Multi-select values are joined with commas. Organizations on GitHub versions
+// without custom properties have none.
+func getRepositoryPropertyValues(client api.RESTClient, org string) (map[string]map[string]string, error) {
+	values := make(map[string]map[string]string)
+
+	err := getAllPages(client, fmt.Sprintf("orgs/%s/properties/values?per_page=%d", org, restPageSize), func(body []byte) error {
+		var page []struct {
+			Repository_full_name string
+			Properties           []struct {
+				Property_name string
+				Value         interface{}
+			}
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, repository := range page {
+			for _, property := range repository.Properties {
+				var value string
+				switch v := property.Value.(type) {
+				case string:
+					value = v
+				case []interface{}:
+					var parts []string
+					for _, part := range v {
+						parts = append(parts, fmt.Sprint(part))
+					}
+					value = strings.Join(parts, ", ")
+				default:
+					continue
+				}
+
+				if values[repository.Repository_full_name] == nil {
+					values[repository.Repository_full_name] = make(map[string]string)
+				}
+				values[repository.Repository_full_name][property.Property_name] = value
+			}
+		}
+
+		return nil
+	})
+
+	if isNotFound(err) {
+		return values, nil
+	}
+
+	return values, err
+}
+
+// isSensitiveWord tells whether a topic or property value contains one of the sensitiveKeywords as a word.
+func isSensitiveWord(value string) bool {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+
+	for _, word := range words {
+		for _, keyword := range sensitiveKeywords {
+			if word == keyword {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sensitiveMarkers lists the topics and custom properties that mark an internal repository as sensitive.
+func sensitiveMarkers(repository InternalRepository) []string {
+	var markers []string
+	for _, topic := range repository.Repository.Topics {
+		if isSensitiveWord(topic) {
+			markers = append(markers, "topic "+topic)
+		}
+	}
+
+	names := make([]string, 0, len(repository.Properties))
+	for name := range repository.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if isSensitiveWord(repository.Properties[name]) {
+			markers = append(markers, fmt.Sprintf("property %s=%s", name, repository.Properties[name]))
+		}
+	}
+
+	return markers
+}
+
+// formatProperties formats custom property values as name=value pairs sorted by name.
+func formatProperties(properties map[string]string) string {
+	var pairs []string
+	for name, value := range properties {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ", ")
+}
+
+// internalRepositoriesInventory lists the internal repositories of an organization, how many members of
+// the organization read them today and how many enterprise members will also read them after the transfer.
+// Members of the enterprise that owns the organization today are not counted, and the enterprise column is
+// empty when no enterprise is given.
+func internalRepositoriesInventory(org string, internal *InternalRepositories) Inventory {
+	inventory := Inventory{
+		Name:    "Internal repositories: " + org,
+		Columns: []string{"Repository", "Archived", "Topics", "Custom Properties", "Organization Members", "Enterprise Members", "Sensitive", "Recommendation"},
+	}
+
+	var enterpriseMembers string
+	if internal.EnterpriseMembers > 0 {
+		enterpriseMembers = strconv.Itoa(internal.EnterpriseMembers)
+	}
+
+	for _, repository := range internal.Repositories {
+		markers := sensitiveMarkers(repository)
+
+		var recommendation string
+		if len(markers) > 0 {
+			recommendation = "make private before the transfer"
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			repository.Repository.Full_name,
+			strconv.FormatBool(repository.Repository.Archived),
+			strings.Join(repository.Repository.Topics, ", "),
+			formatProperties(repository.Properties),
+			strconv.Itoa(internal.OrganizationMembers),
+			enterpriseMembers,
+			strings.Join(markers, ", "),
+			recommendation,
+		})
+	}
+
+	return inventory
+}
+
+var internalRepositoryExposureRule = Rule{
+	ID:       "internal-repository-exposure",
+	Policy:   "Internal Repository Readers",
+	Category: "repository",
+	Severity: SeverityInfo,
+	Inputs:   []string{"enterprise.members", "organization.members", "organization.repositories.internal"},
+	Scopes:   []string{"read:org", "repo", "read:enterprise"},
+}
+
+var sensitiveInternalRepositoriesRule = Rule{
+	ID:       "internal-repository-sensitive",
+	Policy:   "Sensitive Internal Repositories",
+	Category: "repository",
+	Severity: SeverityHigh,
+	Inputs:   []string{"organization.repositories.internal.topics", "organization.properties.values"},
+	Scopes:   []string{"read:org", "repo", "read:enterprise"},
+}
+
+// auditInternalRepositories lists the internal repositories of an organization and checks who will be
+// able to read them once every enterprise member can.
+func auditInternalRepositories(org string, repositories []Repository, ent string) ([]Finding, []Inventory) {
+	internal, err := getOrganizationInternalRepositories(org, repositories, ent)
+	if err != nil {
+		return []Finding{uncollectedFinding(internalRepositoryExposureRule, err), uncollectedFinding(sensitiveInternalRepositoriesRule, err)}, nil
+	}
+
+	return []Finding{
+		withRuleMetadata(internalRepositoryExposureRule, compareInternalRepositoryExposure(internal)),
+		withRuleMetadata(sensitiveInternalRepositoriesRule, compareSensitiveInternalRepositories(internal)),
+	}, []Inventory{internalRepositoriesInventory(org, internal)}
+}
+
+func compareInternalRepositoryExposure(internal *InternalRepositories) Finding {
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d internal repositories, %d Organization members", len(internal.Repositories), internal.OrganizationMembers),
+		TargetValue:    fmt.Sprintf("%d Enterprise members", internal.EnterpriseMembers),
+		EffectiveValue: fmt.Sprintf("readable by %d more Enterprise members", internal.EnterpriseMembers),
+	}
+
+	if len(internal.Repositories) == 0 {
+		finding.Comment = "The Organization has no internal repositories."
+		finding.EffectiveValue = "no internal repositories"
+		finding.Status = statusPass
+		return finding
+	}
+
+	var names []string
+	for _, repository := range internal.Repositories {
+		names = append(names, repository.Repository.Full_name)
+	}
+
+	// reported for information, sensitive repositories are failed by compareSensitiveInternalRepositories
+	finding.Comment = fmt.Sprintf("Every one of the %d Enterprise members will be able to read the internal repositories %s.", internal.EnterpriseMembers, strings.Join(names, ", "))
+	finding.Status = statusPass
+
+	return finding
+}
+
+func compareSensitiveInternalRepositories(internal *InternalRepositories) Finding {
+	var sensitive []string
+	for _, repository := range internal.Repositories {
+		if markers := sensitiveMarkers(repository); len(markers) > 0 {
+			sensitive = append(sensitive, fmt.Sprintf("%s (%s)", repository.Repository.Full_name, strings.Join(markers, ", ")))
+		}
+	}
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d sensitive internal repositories", len(sensitive)),
+		TargetValue:    "internal",
+		EffectiveValue: "internal",
+	}
+
+	if len(sensitive) == 0 {
+		finding.Comment = "No internal repository has a sensitive topic or custom property value."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The internal repositories %s are marked sensitive and will be readable by every Enterprise member.", strings.Join(sensitive, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Make these repositories private before the transfer."
+
+	return finding
+}
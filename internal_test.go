@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestSensitiveMarkers(t *testing.T) {
+	repository := InternalRepository{
+		Repository: Repository{Full_name: "octodemo/payroll", Topics: []string{"api", "pii-data", "philosophy"}},
+		Properties: map[string]string{"data_classification": "Restricted", "team": "payroll"},
+	}
+
+	markers := sensitiveMarkers(repository)
+	if len(markers) != 2 || markers[0] != "topic pii-data" || markers[1] != "property data_classification=Restricted" {
+		t.Errorf("unexpected markers %v", markers)
+	}
+}
+
+func TestCompareInternalRepositories(t *testing.T) {
+	internal := &InternalRepositories{
+		OrganizationMembers: 40,
+		EnterpriseMembers:   1200,
+		Repositories: []InternalRepository{
+			{Repository: Repository{Full_name: "octodemo/handbook", Topics: []string{"docs"}}},
+			{Repository: Repository{Full_name: "octodemo/payroll"}, Properties: map[string]string{"compliance": "sox"}},
+		},
+	}
+
+	finding := compareInternalRepositoryExposure(internal)
+	want := "Every one of the 1200 Enterprise members will be able to read the internal repositories octodemo/handbook, octodemo/payroll."
+	if finding.Status != statusPass || finding.Comment != want || finding.EffectiveValue != "readable by 1200 more Enterprise members" {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	finding = compareSensitiveInternalRepositories(internal)
+	want = "The internal repositories octodemo/payroll (property compliance=sox) are marked sensitive and will be readable by every Enterprise member."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	rows := internalRepositoriesInventory("octodemo", internal).Rows
+	if rows[0][7] != "" || rows[1][5] != "1200" || rows[1][7] != "make private before the transfer" {
+		t.Errorf("unexpected inventory %v", rows)
+	}
+
+	if finding := compareInternalRepositoryExposure(&InternalRepositories{}); finding.Status != statusPass {
+		t.Errorf("expected no internal repositories to pass, got %+v", finding)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/api"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// ipAllowListPageSize is how many IP allow list entries are requested per page.
+const ipAllowListPageSize = 100
+
+type PageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+type IpAllowListEntry struct {
+	Name           string
+	AllowListValue string
+	IsActive       bool
+	CreatedAt      string
+	UpdatedAt      string
+}
+
+// IpAllowListEntries is one page of an ipAllowListEntries connection. Once fetched by
+// getOrganizationGQLPolicies or getEnterprisePolicies, Nodes holds every entry.
+type IpAllowListEntries struct {
+	Nodes    []IpAllowListEntry
+	PageInfo PageInfo
+}
+
+// values returns the sorted allow list values of every entry, for example "192.0.2.0/24, 198.51.100.7",
+// so that two allow lists with the same entries compare equal whatever order they were returned in.
+func (e IpAllowListEntries) values() string {
+	values := make([]string, 0, len(e.Nodes))
+	for _, entry := range e.Nodes {
+		values = append(values, entry.AllowListValue)
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, ", ")
+}
+
+// inactiveValues returns the allow list values of the entries that are not active.
+func (e IpAllowListEntries) inactiveValues() string {
+	var values []string
+	for _, entry := range e.Nodes {
+		if !entry.IsActive {
+			values = append(values, entry.AllowListValue)
+		}
+	}
+
+	return strings.Join(values, ", ")
+}
+
+type organizationIpAllowListPage struct {
+	Organization struct {
+		IpAllowListEntries IpAllowListEntries `graphql:"ipAllowListEntries(first: $first, after: $after)"`
+	} `graphql:"organization(login: $login)"`
+}
+
+type enterpriseIpAllowListPage struct {
+	Enterprise struct {
+		OwnerInfo struct {
+			IpAllowListEntries IpAllowListEntries `graphql:"ipAllowListEntries(first: $first, after: $after)"`
+		}
+	} `graphql:"enterprise(slug: $slug)"`
+}
+
+// fetchRemainingOrganizationIpAllowListEntries follows the cursor of a first page of organization
+// IP allow list entries and appends every following page to it.
+func fetchRemainingOrganizationIpAllowListEntries(client api.GQLClient, org string, entries *IpAllowListEntries) error {
+	for entries.PageInfo.HasNextPage {
+		page := new(organizationIpAllowListPage)
+
+		variables := map[string]interface{}{
+			"login": graphql.String(org),
+			"first": graphql.Int(ipAllowListPageSize),
+			"after": graphql.String(entries.PageInfo.EndCursor),
+		}
+
+		if err := client.Query("OrganizationIpAllowListEntries", page, variables); err != nil {
+			return err
+		}
+
+		entries.Nodes = append(entries.Nodes, page.Organization.IpAllowListEntries.Nodes...)
+		entries.PageInfo = page.Organization.IpAllowListEntries.PageInfo
+	}
+
+	return nil
+}
+
+// fetchRemainingEnterpriseIpAllowListEntries follows the cursor of a first page of enterprise
+// IP allow list entries and appends every following page to it.
+func fetchRemainingEnterpriseIpAllowListEntries(client api.GQLClient, ent string, entries *IpAllowListEntries) error {
+	for entries.PageInfo.HasNextPage {
+		page := new(enterpriseIpAllowListPage)
+
+		variables := map[string]interface{}{
+			"slug":  graphql.String(ent),
+			"first": graphql.Int(ipAllowListPageSize),
+			"after": graphql.String(entries.PageInfo.EndCursor),
+		}
+
+		if err := client.Query("EnterpriseIpAllowListEntries", page, variables); err != nil {
+			return err
+		}
+
+		entries.Nodes = append(entries.Nodes, page.Enterprise.OwnerInfo.IpAllowListEntries.Nodes...)
+		entries.PageInfo = page.Enterprise.OwnerInfo.IpAllowListEntries.PageInfo
+	}
+
+	return nil
+}
+
+// ipAllowListInventory lists every IP allow list entry of an organization or enterprise.
+func ipAllowListInventory(owner string, entries IpAllowListEntries) Inventory {
+	inventory := Inventory{
+		Name:    "IP allow list: " + owner,
+		Columns: []string{"Name", "Value", "Active", "Created", "Updated"},
+	}
+
+	for _, entry := range entries.Nodes {
+		inventory.Rows = append(inventory.Rows, []string{
+			entry.Name,
+			entry.AllowListValue,
+			strconv.FormatBool(entry.IsActive),
+			entry.CreatedAt,
+			entry.UpdatedAt,
+		})
+	}
+
+	return inventory
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func testIpAllowListEntries(values ...string) IpAllowListEntries {
+	var entries IpAllowListEntries
+	for _, value := range values {
+		entries.Nodes = append(entries.Nodes, IpAllowListEntry{Name: "office", AllowListValue: value, IsActive: true})
+	}
+
+	return entries
+}
+
+func TestIpAllowListInventory(t *testing.T) {
+	entries := testIpAllowListEntries("192.0.2.0/24", "198.51.100.7")
+	entries.Nodes[1].IsActive = false
+
+	if got := entries.values(); got != "192.0.2.0/24, 198.51.100.7" {
+		t.Errorf("unexpected values %q", got)
+	}
+
+	inventory := ipAllowListInventory("octodemo", entries)
+	if len(inventory.Rows) != 2 {
+		t.Fatalf("expected a row per entry, got %v", inventory.Rows)
+	}
+	if inventory.Rows[1][1] != "198.51.100.7" || inventory.Rows[1][2] != "false" {
+		t.Errorf("unexpected row %v", inventory.Rows[1])
+	}
+}
+
+func TestCompareIpAllowListEntries(t *testing.T) {
+	org := new(OrganizationPolicies)
+	org.GQL.Organization.IpAllowListEntries = testIpAllowListEntries("192.0.2.0/24", "198.51.100.7")
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.IpAllowListEntries = testIpAllowListEntries("192.0.2.0/24")
+
+	if finding := compareIpAllowListEntries(org, ent); finding.Status != statusPass {
+		t.Errorf("expected a disabled Enterprise allow list to pass, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting = "ENABLED"
+
+	if finding := compareIpAllowListEntries(org, ent); finding.Status != statusPass {
+		t.Errorf("expected the entries of a disabled Organization allow list to pass, got %+v", finding)
+	}
+
+	org.GQL.Organization.IpAllowListEnabledSetting = "ENABLED"
+
+	finding := compareIpAllowListEntries(org, ent)
+	if finding.Status != statusFail || finding.Remediation != "Add 198.51.100.7 to the Enterprise allow list before the transfer." {
+		t.Errorf("expected the entry missing from the Enterprise to fail, got %+v", finding)
+	}
+}
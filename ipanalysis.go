@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Coverage of an organization allow list entry by the enterprise allow list.
+const (
+	coverageCovered   = "covered"
+	coveragePartial   = "partially covered"
+	coverageUncovered = "not covered"
+	coverageInvalid   = "invalid"
+)
+
+// parseAllowListValue parses an allow list value, either a CIDR range or a single address, as a prefix.
+func parseAllowListValue(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// prefixContains reports whether outer contains every address of inner.
+func prefixContains(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// splitPrefix splits a prefix into its lower and upper halves.
+func splitPrefix(p netip.Prefix) (netip.Prefix, netip.Prefix) {
+	bits := p.Bits()
+
+	upper := p.Addr().AsSlice()
+	upper[bits/8] |= 0x80 >> (bits % 8)
+	upperAddr, _ := netip.AddrFromSlice(upper)
+
+	return netip.PrefixFrom(p.Addr(), bits+1), netip.PrefixFrom(upperAddr, bits+1)
+}
+
+// prefixCoveredBy reports whether every address of p is in one of ranges. A prefix can be covered by
+// several smaller ranges together, so it is split in halves wherever a range only overlaps it.
+func prefixCoveredBy(p netip.Prefix, ranges []netip.Prefix) bool {
+	overlapping := false
+	for _, r := range ranges {
+		if prefixContains(r, p) {
+			return true
+		}
+		if r.Overlaps(p) {
+			overlapping = true
+		}
+	}
+
+	if !overlapping || p.Bits() == p.Addr().BitLen() {
+		return false
+	}
+
+	lower, upper := splitPrefix(p)
+
+	return prefixCoveredBy(lower, ranges) && prefixCoveredBy(upper, ranges)
+}
+
+// ipAllowListCoverage is how an organization allow list entry is covered by the enterprise allow list.
+// CoveredBy lists the active enterprise entries overlapping it, InactiveCoveredBy the inactive ones.
+type ipAllowListCoverage struct {
+	Entry             IpAllowListEntry
+	Coverage          string
+	CoveredBy         []string
+	InactiveCoveredBy []string
+}
+
+// analyzeIpAllowListCoverage checks every organization allow list entry against the active entries of
+// the enterprise allow list.
+func analyzeIpAllowListCoverage(org, ent IpAllowListEntries) []ipAllowListCoverage {
+	var active []netip.Prefix
+	var activeValues []string
+	var inactive []netip.Prefix
+	var inactiveValues []string
+
+	for _, entry := range ent.Nodes {
+		prefix, err := parseAllowListValue(entry.AllowListValue)
+		if err != nil {
+			continue
+		}
+
+		if entry.IsActive {
+			active = append(active, prefix)
+			activeValues = append(activeValues, entry.AllowListValue)
+		} else {
+			inactive = append(inactive, prefix)
+			inactiveValues = append(inactiveValues, entry.AllowListValue)
+		}
+	}
+
+	coverages := make([]ipAllowListCoverage, 0, len(org.Nodes))
+
+	for _, entry := range org.Nodes {
+		coverage := ipAllowListCoverage{Entry: entry}
+
+		prefix, err := parseAllowListValue(entry.AllowListValue)
+		if err != nil {
+			coverage.Coverage = coverageInvalid
+			coverages = append(coverages, coverage)
+			continue
+		}
+
+		for i, r := range active {
+			if r.Overlaps(prefix) {
+				coverage.CoveredBy = append(coverage.CoveredBy, activeValues[i])
+			}
+		}
+		for i, r := range inactive {
+			if r.Overlaps(prefix) {
+				coverage.InactiveCoveredBy = append(coverage.InactiveCoveredBy, inactiveValues[i])
+			}
+		}
+
+		switch {
+		case prefixCoveredBy(prefix, active):
+			coverage.Coverage = coverageCovered
+		case len(coverage.CoveredBy) > 0:
+			coverage.Coverage = coveragePartial
+		default:
+			coverage.Coverage = coverageUncovered
+		}
+
+		coverages = append(coverages, coverage)
+	}
+
+	return coverages
+}
+
+// ipAllowListRedundancies describes the entries of an allow list that are listed twice or that are
+// contained in a wider entry of the same list.
+func ipAllowListRedundancies(entries IpAllowListEntries) []string {
+	var prefixes []netip.Prefix
+	var values []string
+
+	for _, entry := range entries.Nodes {
+		if prefix, err := parseAllowListValue(entry.AllowListValue); err == nil {
+			prefixes = append(prefixes, prefix)
+			values = append(values, entry.AllowListValue)
+		}
+	}
+
+	var redundancies []string
+
+	for i := range prefixes {
+		for j := range prefixes {
+			if i == j || !prefixContains(prefixes[j], prefixes[i]) {
+				continue
+			}
+
+			if prefixes[i] == prefixes[j] {
+				// report a duplicate once, at its second occurrence
+				if j < i {
+					redundancies = append(redundancies, fmt.Sprintf("%s duplicates %s", values[i], values[j]))
+					break
+				}
+				continue
+			}
+
+			redundancies = append(redundancies, fmt.Sprintf("%s is within %s", values[i], values[j]))
+			break
+		}
+	}
+
+	return redundancies
+}
+
+// ipAllowListCoverageInventory lists how every organization allow list entry is covered by the enterprise.
+func ipAllowListCoverageInventory(org string, ent string, coverages []ipAllowListCoverage) Inventory {
+	inventory := Inventory{
+		Name:    fmt.Sprintf("IP allow list coverage: %s in %s", org, ent),
+		Columns: []string{"Name", "Value", "Active", "Coverage", "Enterprise Entries", "Inactive Enterprise Entries"},
+	}
+
+	for _, coverage := range coverages {
+		inventory.Rows = append(inventory.Rows, []string{
+			coverage.Entry.Name,
+			coverage.Entry.AllowListValue,
+			strconv.FormatBool(coverage.Entry.IsActive),
+			coverage.Coverage,
+			strings.Join(coverage.CoveredBy, ", "),
+			strings.Join(coverage.InactiveCoveredBy, ", "),
+		})
+	}
+
+	return inventory
+}
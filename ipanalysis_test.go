@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAllowListValue(t *testing.T) {
+	tests := map[string]string{
+		"192.0.2.7":     "192.0.2.7/32",
+		"192.0.2.7/24":  "192.0.2.0/24",
+		" 10.0.0.0/8 ":  "10.0.0.0/8",
+		"2001:db8::1":   "2001:db8::1/128",
+		"2001:db8::/32": "2001:db8::/32",
+	}
+
+	for value, want := range tests {
+		prefix, err := parseAllowListValue(value)
+		if err != nil || prefix.String() != want {
+			t.Errorf("parseAllowListValue(%q) = %v, %v, want %s", value, prefix, err, want)
+		}
+	}
+
+	if _, err := parseAllowListValue("office"); err == nil {
+		t.Error("expected an error for a value that is not an address")
+	}
+}
+
+func TestAnalyzeIpAllowListCoverage(t *testing.T) {
+	org := testIpAllowListEntries("10.1.0.0/16", "192.0.2.0/24", "198.51.100.0/24", "203.0.113.9", "not-an-ip")
+	ent := testIpAllowListEntries("10.0.0.0/8", "192.0.2.0/25", "192.0.2.128/25", "198.51.100.0/26", "203.0.113.0/24")
+	ent.Nodes[4].IsActive = false
+
+	var got []string
+	for _, coverage := range analyzeIpAllowListCoverage(org, ent) {
+		got = append(got, coverage.Coverage)
+	}
+
+	want := []string{coverageCovered, coverageCovered, coveragePartial, coverageUncovered, coverageInvalid}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected coverage %v, got %v", want, got)
+	}
+}
+
+func TestIpAllowListRedundancies(t *testing.T) {
+	entries := testIpAllowListEntries("10.0.0.0/8", "10.1.0.0/16", "192.0.2.7", "192.0.2.7/32", "198.51.100.0/24")
+
+	want := []string{"10.1.0.0/16 is within 10.0.0.0/8", "192.0.2.7/32 duplicates 192.0.2.7"}
+	if got := ipAllowListRedundancies(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCompareIpAllowListInactiveEntries(t *testing.T) {
+	org := new(OrganizationPolicies)
+	org.GQL.Organization.IpAllowListEntries = testIpAllowListEntries("203.0.113.9")
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.IpAllowListEnabledSetting = "ENABLED"
+	ent.Enterprise.OwnerInfo.IpAllowListEntries = testIpAllowListEntries("203.0.113.0/24")
+	ent.Enterprise.OwnerInfo.IpAllowListEntries.Nodes[0].IsActive = false
+
+	finding := compareIpAllowListInactiveEntries(org, ent)
+	if finding.Status != statusFail || finding.Remediation != "Activate 203.0.113.0/24 in the Enterprise allow list before the transfer." {
+		t.Errorf("expected the inactive Enterprise entry to be flagged, got %+v", finding)
+	}
+}
@@ -3,9 +3,9 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strconv"
 
 	"github.com/cli/go-gh"
 	"github.com/cli/go-gh/pkg/repository"
@@ -23,18 +23,110 @@ func main() {
 func cli() error {
 	var organization string
 	var enterprise string
+	var compareOrganization string
+	var compareEnterprise string
+	var baselinePath string
+	var rulesPath string
+	var minScore int
+	var failOn string
+	var format string
+	var output string
 	var repo repository.Repository
 	var err error
 	// isTerminal := term.IsTerminal(os.Stdout)
 
 	flag.StringVar(&organization, "organization", "", "organization")
 	flag.StringVar(&enterprise, "enterprise", "", "enterprise")
+	flag.StringVar(&compareEnterprise, "compare-enterprise", "", "target enterprise to compare -enterprise with")
+	flag.StringVar(&compareOrganization, "compare-organization", "", "reference organization to compare -organization with")
+	flag.StringVar(&baselinePath, "baseline", "", "YAML or JSON baseline file to check -organization against")
+	flag.StringVar(&rulesPath, "rules", "", "YAML or JSON file of custom rule expressions to evaluate with the built-in comparisons (requires -organization and -enterprise, or -baseline)")
+	flag.StringVar(&format, "format", "table", "output format for findings (table, csv, json, pdf)")
+	flag.StringVar(&output, "output", "", "file to write findings to (default stdout, transfer-audit.pdf for pdf)")
+	flag.IntVar(&minScore, "min-score", 0, "fail when the transfer readiness score is below this value")
+	flag.StringVar(&failOn, "fail-on", "", "fail when a finding of this severity or higher is found (info, low, medium, high, blocker)")
 
 	flag.Parse()
 
 	fmt.Println("Organization:", organization)
 	fmt.Println("Enterprise:", enterprise)
 
+	// custom rules only produce findings where a report is published against an enterprise or a baseline
+	if rulesPath != "" && (compareEnterprise != "" || compareOrganization != "") {
+		return fmt.Errorf("-rules cannot be combined with -compare-enterprise or -compare-organization")
+	}
+	if rulesPath != "" && baselinePath == "" && (organization == "" || enterprise == "") {
+		return fmt.Errorf("-rules requires both -organization and -enterprise, or -baseline")
+	}
+
+	var customRules []Rule
+	if rulesPath != "" {
+		customRules, err = loadCustomRules(rulesPath)
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range customRules {
+			if err := addRule(rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	// if a target enterprise is provided, compare the two enterprises with each other
+	if compareEnterprise != "" {
+		if enterprise == "" || organization != "" || compareOrganization != "" {
+			return fmt.Errorf("-compare-enterprise requires -enterprise and cannot be combined with -organization or -compare-organization")
+		}
+
+		fmt.Println("Target enterprise provided. Retrieving policies for both enterprises and comparing them.")
+
+		entPolicies, err := getEnterprisePolicies(enterprise)
+		if err != nil {
+			return err
+		}
+
+		targetPolicies, err := getEnterprisePolicies(compareEnterprise)
+		if err != nil {
+			return err
+		}
+
+		report := Report{
+			Source:   enterprise,
+			Target:   compareEnterprise,
+			Findings: compareEnterprises(enterprise, entPolicies, compareEnterprise, targetPolicies),
+		}
+
+		return publishReport(report, format, output, minScore, failOn)
+	}
+
+	// if a reference organization is provided, compare the two organizations with each other
+	if compareOrganization != "" {
+		if organization == "" || enterprise != "" {
+			return fmt.Errorf("-compare-organization requires -organization and cannot be combined with -enterprise")
+		}
+
+		fmt.Println("Reference organization provided. Retrieving policies for both organizations and comparing them.")
+
+		orgPolicies, err := getOrganizationPolicies(organization)
+		if err != nil {
+			return err
+		}
+
+		referencePolicies, err := getOrganizationPolicies(compareOrganization)
+		if err != nil {
+			return err
+		}
+
+		report := Report{
+			Source:   organization,
+			Target:   compareOrganization,
+			Findings: compareOrganizations(organization, orgPolicies, compareOrganization, referencePolicies),
+		}
+
+		return publishReport(report, format, output, minScore, failOn)
+	}
+
 	// if organization and enterprise are not provided, get the current repository
 	if organization == "" && enterprise == "" {
 		fmt.Println("No organization or enterprise provided. Retrieving policies current organization of current repository.")
@@ -56,6 +148,38 @@ func cli() error {
 
 	}
 
+	// if a baseline is provided, check the organization against it without enterprise access
+	if baselinePath != "" {
+		if enterprise != "" || compareOrganization != "" {
+			return fmt.Errorf("-baseline cannot be combined with -enterprise or -compare-organization")
+		}
+
+		baseline, err := loadBaseline(baselinePath)
+		if err != nil {
+			return err
+		}
+
+		orgPolicies, err := getOrganizationPolicies(organization)
+		if err != nil {
+			return err
+		}
+
+		findings := checkBaseline(orgPolicies, baseline)
+
+		// custom rules can still check the organization, enterprise fields are unknown
+		for _, rule := range customRules {
+			findings = append(findings, evaluateRule(rule, orgPolicies, nil))
+		}
+
+		report := Report{
+			Source:   organization,
+			Target:   baseline.Name,
+			Findings: findings,
+		}
+
+		return publishReport(report, format, output, minScore, failOn)
+	}
+
 	var entPolicies *EnterprisePolicies
 	var error error
 
@@ -72,46 +196,150 @@ func cli() error {
 
 		tablePrintEntPolicies(*entPolicies)
 
-	}
+		tablePrintInventory(os.Stdout, ipAllowListInventory(enterprise, entPolicies.Enterprise.OwnerInfo.IpAllowListEntries))
+
+		if entPolicies.Enterprise.OwnerInfo.SamlIdentityProvider.Id != "" {
+			tablePrintInventory(os.Stdout, samlProviderInventory(enterprise, entPolicies.Enterprise.OwnerInfo.SamlIdentityProvider))
+		}
+
+		entActions, err := getEnterpriseActionsPolicies(enterprise)
+		if err != nil {
+			fmt.Println("Could not collect the Enterprise Actions policies:", err)
+		} else {
+			tablePrintInventory(os.Stdout, settingsInventory("Actions policies: "+enterprise, actionsSettings(*entActions)))
+		}
+
+		entGroups, err := getEnterpriseRunnerGroups(enterprise)
+		if err != nil {
+			fmt.Println("Could not collect the Enterprise runner groups:", err)
+		} else {
+			tablePrintInventory(os.Stdout, runnerGroupsInventory(enterprise, entGroups))
+			tablePrintInventory(os.Stdout, runnersInventory(enterprise, entGroups))
+		}
 
-	var orgGQLPolicies *OrganizationGQLPolicies
-	var orgRESTPolicies *OrganizationRESTPolicies
+	}
 
 	// if only organization is provided, get the organization policies
 	if organization != "" && enterprise == "" {
 		fmt.Println("No enterprise provided. Retrieving policies for organization.")
 
-		// Get organization GraphQL policies
-		orgGQLPolicies, error = getOrganizationGQLPolicies(organization)
+		orgPolicies, error := getOrganizationPolicies(organization)
 
 		if error != nil {
 			log.Fatal(error)
 		}
 
-		fmt.Println("Organization GQL Policies", orgGQLPolicies)
+		tablePrintOrgPolicies(*orgPolicies)
 
-		orgRESTPolicies, error = getOrganizationRESTPolicies(organization)
+		tablePrintInventory(os.Stdout, ipAllowListInventory(organization, orgPolicies.GQL.Organization.IpAllowListEntries))
 
-		if error != nil {
-			log.Fatal(error)
+		if orgPolicies.GQL.Organization.SamlIdentityProvider.Id != "" {
+			tablePrintInventory(os.Stdout, samlProviderInventory(organization, orgPolicies.GQL.Organization.SamlIdentityProvider))
 		}
 
-		fmt.Println("Organization REST Policies", orgRESTPolicies)
+		orgActions, err := getOrganizationActionsPolicies(organization)
+		if err != nil {
+			fmt.Println("Could not collect the Organization Actions policies:", err)
+		} else {
+			tablePrintInventory(os.Stdout, settingsInventory("Actions policies: "+organization, actionsSettings(*orgActions)))
+		}
 
-		// Create a new orgPolicies object with the REST and GQL policies
-		orgPolicies := OrganizationPolicies{
-			GQL:  *orgGQLPolicies,
-			REST: *orgRESTPolicies,
+		orgGroups, err := getOrganizationRunnerGroups(organization)
+		if err != nil {
+			fmt.Println("Could not collect the Organization runner groups:", err)
+		} else {
+			tablePrintInventory(os.Stdout, runnerGroupsInventory(organization, orgGroups))
+			tablePrintInventory(os.Stdout, runnersInventory(organization, orgGroups))
 		}
 
-		tablePrintOrgPolicies(orgPolicies)
+		installations, err := getOrganizationAppInstallations(organization)
+		if err != nil {
+			fmt.Println("Could not collect the Organization's GitHub App installations:", err)
+		} else {
+			tablePrintInventory(os.Stdout, appInstallationsInventory(organization, installations, nil))
+		}
+
+		oauthAccess, err := getOrganizationOAuthAppAccess(organization)
+		if err != nil {
+			fmt.Println("Could not collect the Organization's OAuth app access:", err)
+		} else {
+			tablePrintInventory(os.Stdout, oauthAppsInventory(organization, oauthAccess))
+		}
+
+		twoFactorUsers, err := getTwoFactorDisabledUsers(organization)
+		if err != nil {
+			fmt.Println("Could not collect the Organization's users without two factor authentication:", err)
+		} else {
+			tablePrintInventory(os.Stdout, twoFactorInventory(organization, twoFactorUsers, nil))
+		}
+
+		// the repositories are listed once and shared by every inventory that covers them
+		repositories, err := listOrganizationRepositories(organization)
+		if err != nil {
+			fmt.Println("Could not collect the Organization's repositories:", err)
+		} else {
+			repositoryInventory, err := getOrganizationRepositoryInventory(organization, repositories)
+			if err != nil {
+				fmt.Println("Could not collect the forks of the Organization's repositories:", err)
+			} else {
+				tablePrintInventory(os.Stdout, repositoriesInventory(organization, repositoryInventory, nil))
+				tablePrintInventory(os.Stdout, privateForksInventory(organization, repositoryInventory, nil))
+			}
+
+			internalRepositories, err := getOrganizationInternalRepositories(organization, repositories, "")
+			if err != nil {
+				fmt.Println("Could not collect the Organization's internal repositories:", err)
+			} else {
+				tablePrintInventory(os.Stdout, internalRepositoriesInventory(organization, internalRepositories))
+			}
+
+			webhooks, err := getOrganizationWebhooks(organization, repositories)
+			if err != nil {
+				fmt.Println("Could not collect the Organization's webhooks:", err)
+			} else {
+				tablePrintInventory(os.Stdout, webhooksInventory(organization, webhooks))
+			}
+
+			secrets, err := getOrganizationSecrets(organization, repositories)
+			if err != nil {
+				fmt.Println("Could not collect the Organization's secrets and variables:", err)
+			} else {
+				tablePrintInventory(os.Stdout, secretsInventory(organization, secrets))
+			}
+
+			security, err := getOrganizationSecurity(organization, repositories)
+			if err != nil {
+				fmt.Println("Could not collect the Organization's security coverage:", err)
+			} else {
+				tablePrintInventory(os.Stdout, repositorySecurityInventory(organization, security))
+				tablePrintInventory(os.Stdout, settingsInventory("Advanced Security licenses: "+organization, advancedSecuritySettings(security.Committers)))
+			}
+
+			collaborations, err := getOrganizationCollaborations(organization, repositories)
+			if err != nil {
+				fmt.Println("Could not collect the Organization's outside collaborators:", err)
+			} else {
+				tablePrintInventory(os.Stdout, collaborationsInventory(organization, collaborations, nil))
+			}
+		}
+
+		members, err := getOrganizationMembers(organization)
+		if err == nil {
+			var identities []ExternalIdentity
+			if identities, err = getOrganizationExternalIdentities(organization); err == nil {
+				tablePrintInventory(os.Stdout, samlCoverageInventory(organization, analyzeSamlIdentityCoverage(members, identities, nil, false), false))
+			}
+		}
+		if err != nil {
+			fmt.Println("Could not collect the Organization's SAML identities:", err)
+		}
 	}
 
 	// if both are provided, get the both policies and compare them
 	if organization != "" && enterprise != "" {
 		fmt.Println("Both enterprise and organization provided. Retrieving policies for both and comparing them.")
-		// Get organization policies
-		orgGQLPolicies, error = getOrganizationGQLPolicies(organization)
+		// Get organization GraphQL and REST policies
+		orgPolicies, error := getOrganizationPolicies(organization)
 
 		if error != nil {
 			log.Fatal(error)
@@ -124,11 +352,127 @@ func cli() error {
 			log.Fatal(error)
 		}
 
-		comparison := comparePolicies(orgGQLPolicies, entPolicies)
+		findings := comparePolicies(orgPolicies, entPolicies)
+
+		actionsFindings, inventories := auditActions(organization, enterprise)
+		findings = append(findings, actionsFindings...)
+
+		runnerFindings, runnerInventories := auditRunners(organization, enterprise)
+		findings = append(findings, runnerFindings...)
+		inventories = append(inventories, runnerInventories...)
+
+		appFindings, appInventories := auditAppInstallations(organization, orgPolicies, entPolicies)
+		findings = append(findings, appFindings...)
+		inventories = append(inventories, appInventories...)
+
+		oauthFindings, oauthInventories := auditOAuthApps(organization, enterprise)
+		findings = append(findings, oauthFindings...)
+		inventories = append(inventories, oauthInventories...)
+
+		twoFactorFindings, twoFactorInventories := auditTwoFactorUsers(organization, entPolicies)
+		findings = append(findings, twoFactorFindings...)
+		inventories = append(inventories, twoFactorInventories...)
+		reconcileTwoFactorFindings(findings)
+
+		samlFindings, samlInventories := auditSamlIdentities(organization, enterprise, entPolicies)
+		findings = append(findings, samlFindings...)
+		inventories = append(inventories, samlInventories...)
+
+		// the repositories are listed once and shared by every audit that covers them
+		repositories, err := listOrganizationRepositories(organization)
+		if err != nil {
+			findings = append(findings, uncollectedRepositoryFindings(err)...)
+		} else {
+			collaboratorFindings, collaboratorInventories := auditCollaborators(organization, repositories, entPolicies)
+			findings = append(findings, collaboratorFindings...)
+			inventories = append(inventories, collaboratorInventories...)
+
+			repositoryFindings, repositoryInventories := auditRepositories(organization, repositories, entPolicies)
+			findings = append(findings, repositoryFindings...)
+			inventories = append(inventories, repositoryInventories...)
+
+			internalFindings, internalInventories := auditInternalRepositories(organization, repositories, enterprise)
+			findings = append(findings, internalFindings...)
+			inventories = append(inventories, internalInventories...)
+
+			webhookFindings, webhookInventories := auditWebhooks(organization, repositories)
+			findings = append(findings, webhookFindings...)
+			inventories = append(inventories, webhookInventories...)
+
+			secretFindings, secretInventories := auditSecrets(organization, repositories)
+			findings = append(findings, secretFindings...)
+			inventories = append(inventories, secretInventories...)
+
+			securityFindings, securityInventories := auditSecurity(organization, repositories, orgPolicies, enterprise)
+			findings = append(findings, securityFindings...)
+			inventories = append(inventories, securityInventories...)
+		}
+
+		policyInventories := []Inventory{
+			ipAllowListInventory(organization, orgPolicies.GQL.Organization.IpAllowListEntries),
+			ipAllowListInventory(enterprise, entPolicies.Enterprise.OwnerInfo.IpAllowListEntries),
+			ipAllowListCoverageInventory(organization, enterprise, analyzeIpAllowListCoverage(
+				orgPolicies.GQL.Organization.IpAllowListEntries,
+				entPolicies.Enterprise.OwnerInfo.IpAllowListEntries,
+			)),
+		}
+
+		if orgPolicies.GQL.Organization.SamlIdentityProvider.Id != "" {
+			policyInventories = append(policyInventories, samlProviderInventory(organization, orgPolicies.GQL.Organization.SamlIdentityProvider))
+		}
+		if entPolicies.Enterprise.OwnerInfo.SamlIdentityProvider.Id != "" {
+			policyInventories = append(policyInventories, samlProviderInventory(enterprise, entPolicies.Enterprise.OwnerInfo.SamlIdentityProvider))
+		}
+
+		report := Report{
+			Source:      organization,
+			Target:      enterprise,
+			Findings:    findings,
+			Effective:   resolveEffectivePolicies(orgPolicies, entPolicies),
+			Inventories: append(policyInventories, inventories...),
+		}
 
-		fmt.Println(comparison)
+		if err := publishReport(report, format, output, minScore, failOn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// publishReport renders a report, prints the transfer readiness of its source and applies the
+// -min-score and -fail-on gates.
+func publishReport(report Report, format string, output string, minScore int, failOn string) error {
+	if err := writeReport(report, format, output); err != nil {
+		return err
+	}
+
+	readiness := transferReadiness(report.Source, report.Findings)
+	fmt.Println(readiness)
+
+	return checkReadinessGate(readiness, report.Findings, minScore, failOn)
+}
+
+// checkReadinessGate returns an error when the findings do not meet the -min-score or -fail-on gates.
+func checkReadinessGate(readiness Readiness, findings []Finding, minScore int, failOn string) error {
+	if readiness.Score < minScore {
+		return fmt.Errorf("transfer readiness score %d is below the minimum of %d", readiness.Score, minScore)
+	}
+
+	if failOn == "" {
+		return nil
+	}
+
+	threshold, err := parseSeverity(failOn)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		if finding.Status == statusFail && finding.Severity >= threshold {
+			return fmt.Errorf("%s has a %s finding: %s", finding.Policy, finding.Severity, finding.Comment)
+		}
 	}
-	// createCSV(orgPolicies, entPolicies, comparePolicies(orgPolicies, entPolicies))
 
 	return nil
 }
@@ -140,57 +484,12 @@ func tablePrintOrgPolicies(orgPolicies OrganizationPolicies) {
 	tp.AddField("Policy Name", tableprinter.WithColor(bold))
 	tp.AddField("Policy Value")
 	tp.EndRow()
-	tp.AddField("HasOrganizationProjects")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Has_organization_projects))
-	tp.EndRow()
-	tp.AddField("HasRepositoryProjects")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Has_repository_projects))
-	tp.EndRow()
-	tp.AddField("DefaultRepositoryPermission")
-	tp.AddField(orgPolicies.REST.Default_repository_permission)
-	tp.EndRow()
-	tp.AddField("MembersCanCreateRepositories")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Members_can_create_repositories))
-	tp.EndRow()
-	tp.AddField("TwoFactorRequirementEnabled")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Two_factor_requirement_enabled))
-	tp.EndRow()
-	tp.AddField("MembersAllowedRepositoryCreationType")
-	tp.AddField(orgPolicies.REST.Members_allowed_repository_creation_type)
-	tp.EndRow()
-	tp.AddField("MembersCanCreatePublicRepositories")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Members_can_create_public_repositories))
-	tp.EndRow()
-	tp.AddField("MembersCanCreatePrivateRepositories")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Members_can_create_private_repositories))
-	tp.EndRow()
-	tp.AddField("MembersCanCreateInternalRepositories")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Members_can_create_internal_repositories))
-	tp.EndRow()
-	tp.AddField("MembersCanCreatePages")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Members_can_create_pages))
-	tp.EndRow()
-	tp.AddField("MembersCanForkPrivateRepositoriesREST")
-	tp.AddField(strconv.FormatBool(orgPolicies.REST.Members_can_fork_private_repositories))
-	tp.EndRow()
-	tp.AddField("IpAllowListEnabledSetting")
-	tp.AddField(orgPolicies.GQL.Organization.IpAllowListEnabledSetting, tableprinter.WithColor(red))
-	tp.EndRow()
-	tp.AddField("IpAllowListEntries")
-	tp.AddField(orgPolicies.GQL.Organization.IpAllowListEntries.Edges.Node.AllowListValue, tableprinter.WithColor(red))
-	tp.EndRow()
-	tp.AddField("IpAllowListForInstalledAppsEnabledSetting")
-	tp.AddField(orgPolicies.GQL.Organization.IpAllowListForInstalledAppsEnabledSetting, tableprinter.WithColor(green))
-	tp.EndRow()
-	tp.AddField("MembersCanForkPrivateRepositories")
-	tp.AddField(strconv.FormatBool(orgPolicies.GQL.Organization.MembersCanForkPrivateRepositories))
-	tp.EndRow()
-	tp.AddField("RequiresTwoFactorAuthentication")
-	tp.AddField(strconv.FormatBool(orgPolicies.GQL.Organization.RequiresTwoFactorAuthentication))
-	tp.EndRow()
-	tp.AddField("SamlIdentityProvider")
-	tp.AddField(orgPolicies.GQL.Organization.SamlIdentityProvider.Id)
-	tp.EndRow()
+
+	for _, setting := range organizationSettings(orgPolicies) {
+		tp.AddField(setting.Key)
+		tp.AddField(setting.Value)
+		tp.EndRow()
+	}
 
 	tp.Render()
 }
@@ -202,63 +501,102 @@ func tablePrintEntPolicies(entPolicies EnterprisePolicies) {
 	tp.AddField("Policy Name")
 	tp.AddField("Policy Value")
 	tp.EndRow()
-	tp.AddField("AllowPrivateRepositoryForkingSettingPolicyValue")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting)
-	// tp.AddField(entPolicies.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSettingPolicyValue)
-	tp.EndRow()
-	tp.AddField("DefaultRepositoryPermissionSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting)
-	tp.EndRow()
-	tp.AddField("IpAllowListEnabledSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.IpAllowListEnabledSetting)
-	tp.EndRow()
-	tp.AddField("IpAllowListEntries")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.IpAllowListEntries.Edges.Node.AllowListValue)
-	tp.EndRow()
-	tp.AddField("IpAllowListForInstalledAppsEnabledSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.IpAllowListForInstalledAppsEnabledSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanChangeRepositoryVisibilitySetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanChangeRepositoryVisibilitySetting)
-	tp.EndRow()
-	tp.AddField("MembersCanCreateRepositoriesSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanDeleteIssuesSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanDeleteIssuesSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanDeleteRepositoriesSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanDeleteRepositoriesSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanInviteCollaboratorsSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanInviteCollaboratorsSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanMakePurchasesSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanMakePurchasesSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanUpdateProtectedBranchesSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanUpdateProtectedBranchesSetting)
-	tp.EndRow()
-	tp.AddField("MembersCanViewDependencyInsightsSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.MembersCanViewDependencyInsightsSetting)
-	tp.EndRow()
-	tp.AddField("OrganizationProjectsSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.OrganizationProjectsSetting)
-	tp.EndRow()
-	tp.AddField("RepositoryProjectsSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.RepositoryProjectsSetting)
-	tp.EndRow()
-	tp.AddField("SamlIdentityProvider")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.SamlIdentityProvider.Id)
-	tp.EndRow()
-	tp.AddField("TeamDiscussionsSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.TeamDiscussionsSetting)
+
+	for _, setting := range enterpriseSettings(entPolicies) {
+		tp.AddField(setting.Key)
+		tp.AddField(setting.Value)
+		tp.EndRow()
+	}
+
+	tp.Render()
+}
+
+func tablePrintFindings(w io.Writer, report Report) error {
+	// have to actually get isTerminal
+	tp := tableprinter.New(w, true, 100)
+
+	tp.AddField("Policy", tableprinter.WithColor(bold))
+	tp.AddField("Category", tableprinter.WithColor(bold))
+	tp.AddField("Severity", tableprinter.WithColor(bold))
+	tp.AddField(report.Source, tableprinter.WithColor(bold))
+	tp.AddField(report.Target, tableprinter.WithColor(bold))
+	tp.AddField("Effective", tableprinter.WithColor(bold))
+	tp.AddField("Status", tableprinter.WithColor(bold))
+	tp.AddField("Comment", tableprinter.WithColor(bold))
+	tp.AddField("Remediation", tableprinter.WithColor(bold))
+	tp.EndRow()
+
+	for _, finding := range report.Findings {
+		tp.AddField(finding.Policy)
+		tp.AddField(finding.Category)
+		if finding.Severity >= SeverityHigh && finding.Status != statusPass {
+			tp.AddField(finding.Severity.String(), tableprinter.WithColor(red))
+		} else {
+			tp.AddField(finding.Severity.String())
+		}
+		tp.AddField(finding.SourceValue)
+		tp.AddField(finding.TargetValue)
+		tp.AddField(finding.EffectiveValue)
+		switch finding.Status {
+		case statusFail:
+			tp.AddField(finding.Status, tableprinter.WithColor(red))
+		case statusPass:
+			tp.AddField(finding.Status, tableprinter.WithColor(green))
+		default:
+			tp.AddField(finding.Status)
+		}
+		tp.AddField(finding.Comment)
+		tp.AddField(finding.Remediation)
+		tp.EndRow()
+	}
+
+	return tp.Render()
+}
+
+func tablePrintEffectiveSettings(w io.Writer, report Report) error {
+	// have to actually get isTerminal
+	tp := tableprinter.New(w, true, 100)
+
+	tp.AddField("Setting", tableprinter.WithColor(bold))
+	tp.AddField("Today", tableprinter.WithColor(bold))
+	tp.AddField("After Transfer", tableprinter.WithColor(bold))
+	tp.AddField("Enforced By", tableprinter.WithColor(bold))
 	tp.EndRow()
-	tp.AddField("TwoFactorRequiredSetting")
-	tp.AddField(entPolicies.Enterprise.OwnerInfo.TwoFactorRequiredSetting)
+
+	for _, setting := range report.Effective {
+		tp.AddField(setting.Key)
+		tp.AddField(setting.Current)
+		if setting.Effective != setting.Current {
+			tp.AddField(setting.Effective, tableprinter.WithColor(red))
+		} else {
+			tp.AddField(setting.Effective)
+		}
+		tp.AddField(setting.EnforcedBy)
+		tp.EndRow()
+	}
+
+	return tp.Render()
+}
+
+func tablePrintInventory(w io.Writer, inventory Inventory) error {
+	fmt.Fprintln(w, inventory.Name)
+
+	// have to actually get isTerminal
+	tp := tableprinter.New(w, true, 100)
+
+	for _, column := range inventory.Columns {
+		tp.AddField(column, tableprinter.WithColor(bold))
+	}
 	tp.EndRow()
 
-	tp.Render()
+	for _, row := range inventory.Rows {
+		for _, field := range row {
+			tp.AddField(field)
+		}
+		tp.EndRow()
+	}
+
+	return tp.Render()
 }
 
 // function that takes in a string and returns that string color red
@@ -281,20 +619,13 @@ type OrganizationPolicies struct {
 
 type OrganizationGQLPolicies struct {
 	Organization struct {
-		IpAllowListEnabledSetting string
-		IpAllowListEntries        struct {
-			Edges struct {
-				Node struct {
-					AllowListValue string
-				}
-			}
-		} `graphql:"ipAllowListEntries(first: $first)"`
-		IpAllowListForInstalledAppsEnabledSetting string
-		MembersCanForkPrivateRepositories         bool
-		RequiresTwoFactorAuthentication           bool
-		SamlIdentityProvider                      struct {
-			Id string
-		}
+		IpAllowListEnabledSetting                     string
+		IpAllowListEntries                            IpAllowListEntries `graphql:"ipAllowListEntries(first: $first)"`
+		IpAllowListForInstalledAppsEnabledSetting     string
+		MembersCanForkPrivateRepositories             bool
+		NotificationDeliveryRestrictionEnabledSetting string
+		RequiresTwoFactorAuthentication               bool
+		SamlIdentityProvider                          SamlIdentityProvider
 	} `graphql:"organization(login: $login)"`
 }
 
@@ -302,18 +633,45 @@ type OrganizationGQLPolicies struct {
 
 // type OrganizationRESTPolicies struct {
 
+// The booleans only returned to organization owners are pointers, so a setting that was not returned
+// is told apart from a disabled one.
 type OrganizationRESTPolicies struct {
 	Has_organization_projects                bool
 	Has_repository_projects                  bool
 	Default_repository_permission            string
-	Members_can_create_repositories          bool
-	Two_factor_requirement_enabled           bool
+	Members_can_create_repositories          *bool
+	Two_factor_requirement_enabled           *bool
 	Members_allowed_repository_creation_type string
-	Members_can_create_public_repositories   bool
-	Members_can_create_private_repositories  bool
-	Members_can_create_internal_repositories bool
-	Members_can_create_pages                 bool
-	Members_can_fork_private_repositories    bool
+	Members_can_create_public_repositories   *bool
+	Members_can_create_private_repositories  *bool
+	Members_can_create_internal_repositories *bool
+	Members_can_create_pages                 *bool
+	Members_can_fork_private_repositories    *bool
+
+	Advanced_security_enabled_for_new_repositories               *bool
+	Dependabot_alerts_enabled_for_new_repositories               *bool
+	Dependabot_security_updates_enabled_for_new_repositories     *bool
+	Dependency_graph_enabled_for_new_repositories                *bool
+	Secret_scanning_enabled_for_new_repositories                 *bool
+	Secret_scanning_push_protection_enabled_for_new_repositories *bool
+}
+
+// getOrganizationPolicies merges the GraphQL and REST policies of an organization.
+func getOrganizationPolicies(org string) (*OrganizationPolicies, error) {
+	gqlPolicies, err := getOrganizationGQLPolicies(org)
+	if err != nil {
+		return nil, err
+	}
+
+	restPolicies, err := getOrganizationRESTPolicies(org)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrganizationPolicies{
+		GQL:  *gqlPolicies,
+		REST: *restPolicies,
+	}, nil
 }
 
 func getOrganizationRESTPolicies(org string) (*OrganizationRESTPolicies, error) {
@@ -345,7 +703,7 @@ func getOrganizationGQLPolicies(org string) (*OrganizationGQLPolicies, error) {
 
 	variables := map[string]interface{}{
 		"login": graphql.String(org),
-		"first": graphql.Int(10),
+		"first": graphql.Int(ipAllowListPageSize),
 	}
 
 	err = client.Query("Organization", &query, variables)
@@ -353,6 +711,11 @@ func getOrganizationGQLPolicies(org string) (*OrganizationGQLPolicies, error) {
 		log.Fatal(err)
 	}
 
+	err = fetchRemainingOrganizationIpAllowListEntries(client, org, &query.Organization.IpAllowListEntries)
+	if err != nil {
+		return nil, err
+	}
+
 	return query, err
 }
 
@@ -365,30 +728,22 @@ type EnterprisePolicies struct {
 			AllowPrivateRepositoryForkingSettingPolicyValue string
 			DefaultRepositoryPermissionSetting              string
 			IpAllowListEnabledSetting                       string
-			IpAllowListEntries                              struct {
-				Edges struct {
-					Node struct {
-						AllowListValue string
-					}
-				}
-			} `graphql:"ipAllowListEntries(first: $first)"`
-			IpAllowListForInstalledAppsEnabledSetting     string
-			MembersCanChangeRepositoryVisibilitySetting   string
-			MembersCanCreateRepositoriesSetting           string
-			MembersCanDeleteIssuesSetting                 string
-			MembersCanDeleteRepositoriesSetting           string
-			MembersCanInviteCollaboratorsSetting          string
-			MembersCanMakePurchasesSetting                string
-			MembersCanUpdateProtectedBranchesSetting      string
-			MembersCanViewDependencyInsightsSetting       string
-			NotificationDeliveryRestrictionEnabledSetting string
-			OrganizationProjectsSetting                   string
-			RepositoryProjectsSetting                     string
-			SamlIdentityProvider                          struct {
-				Id string
-			}
-			TeamDiscussionsSetting   string
-			TwoFactorRequiredSetting string
+			IpAllowListEntries                              IpAllowListEntries `graphql:"ipAllowListEntries(first: $first)"`
+			IpAllowListForInstalledAppsEnabledSetting       string
+			MembersCanChangeRepositoryVisibilitySetting     string
+			MembersCanCreateRepositoriesSetting             string
+			MembersCanDeleteIssuesSetting                   string
+			MembersCanDeleteRepositoriesSetting             string
+			MembersCanInviteCollaboratorsSetting            string
+			MembersCanMakePurchasesSetting                  string
+			MembersCanUpdateProtectedBranchesSetting        string
+			MembersCanViewDependencyInsightsSetting         string
+			NotificationDeliveryRestrictionEnabledSetting   string
+			OrganizationProjectsSetting                     string
+			RepositoryProjectsSetting                       string
+			SamlIdentityProvider                            SamlIdentityProvider
+			TeamDiscussionsSetting                          string
+			TwoFactorRequiredSetting                        string
 		}
 	} `graphql:"enterprise(slug: $slug)"`
 }
@@ -405,162 +760,19 @@ func getEnterprisePolicies(ent string) (*EnterprisePolicies, error) {
 
 	variables := map[string]interface{}{
 		"slug":  graphql.String(ent),
-		"first": graphql.Int(10),
+		"first": graphql.Int(ipAllowListPageSize),
 	}
 
 	err = client.Query("Enterprise", &query, variables)
 	if err != nil {
 		log.Fatal(err)
 	}
-	// fmt.Println(query)
-
-	return query, err
-}
-
-type Compare struct {
-	PrivateRepositoryForking
-	DefaultRepositoryPermissionSetting
-	SamlIdentityProvider
-	TwoFactorAuthenticationSetting
-}
-
-type PrivateRepositoryForking struct {
-	Policy   string `default:"Private Repository Forking"`
-	Category string `default:"repository"`
-	Comment  string
-	Status   string
-}
-
-type DefaultRepositoryPermissionSetting struct {
-	Policy   string `default:"Default Repository Permission Setting"`
-	Category string `default:"repository"`
-	Comment  string
-	Status   string
-}
-
-type SamlIdentityProvider struct {
-	Policy   string `default:"SAML Identity Provider"`
-	Category string `default:"account"`
-	Comment  string
-	Status   string
-}
-
-type TwoFactorAuthenticationSetting struct {
-	Policy   string `default:"Two Factor Authentication Setting"`
-	Category string `default:"account"`
-	Comment  string
-	Status   string
-}
-
-func comparePolicies(org *OrganizationGQLPolicies, ent *EnterprisePolicies) *Compare {
-	fmt.Println("Comparing Organization and Enterprise Policies")
-
-	compare := new(Compare)
-
-	// fmt.Println(`Compare:`, compare)
-
-	compare = comparePrivateRepositoryForking(compare, org, ent)
-
-	// fmt.Println(`Compare 1:`, compare)
-
-	compare = compareTwoFactorAuthentication(compare, org, ent)
-
-	// fmt.Println(`Compare 2:`, compare)
-
-	compare = compareSamlIdentityProvider(compare, org, ent)
-
-	// fmt.Println(`Compare 3:`, compare)
-
-	return compare
-}
-
-func comparePrivateRepositoryForking(compare *Compare, org *OrganizationGQLPolicies, ent *EnterprisePolicies) *Compare {
-	fmt.Println("Comparing Private Repository Forking Policies")
-
-	if ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting == "NO_POLICY" {
-		compare.PrivateRepositoryForking.Comment = "There is no Enterprise policy."
-		compare.PrivateRepositoryForking.Status = "✓"
-		return compare
-	}
-
-	return compare
-}
-
-func compareTwoFactorAuthentication(compare *Compare, org *OrganizationGQLPolicies, ent *EnterprisePolicies) *Compare {
-	fmt.Println("Comparing Two Factor Authentication Policies")
-
-	if ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting == "NO_POLICY" {
-		compare.TwoFactorAuthenticationSetting.Comment = "There is no Enterprise policy."
-		compare.TwoFactorAuthenticationSetting.Status = "✓"
-		return compare
-	}
-
-	if ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting == "ENABLED" {
-		if org.Organization.RequiresTwoFactorAuthentication {
-			compare.TwoFactorAuthenticationSetting.Comment = "The Enterprise and the Organization both have Two Factor Authentication enabled."
-			compare.TwoFactorAuthenticationSetting.Status = "✓"
-		}
-
-		if !org.Organization.RequiresTwoFactorAuthentication {
-			compare.TwoFactorAuthenticationSetting.Comment = "The Enterprise two factor authentication setting will apply to the Organization. Members who do not have two factor authentication enabled will not be removed from the Organization."
-			compare.TwoFactorAuthenticationSetting.Status = "✗"
-		}
-
-		return compare
-
-	}
-
-	return compare
-}
-
-func compareSamlIdentityProvider(compare *Compare, org *OrganizationGQLPolicies, ent *EnterprisePolicies) *Compare {
-	fmt.Println("Comparing SAML Identity Provider Policies")
 
-	if ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id == "" {
-		compare.TwoFactorAuthenticationSetting.Comment = "SAML Single Sign On is not enabled at the Enterprise level."
-		compare.TwoFactorAuthenticationSetting.Status = "✓"
-		return compare
-	}
-
-	if ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id != "" {
-		if org.Organization.SamlIdentityProvider.Id == "" {
-			compare.TwoFactorAuthenticationSetting.Comment = "SAML Single Sign On is enabled at the Enterprise level, but not at the Organization level."
-			compare.TwoFactorAuthenticationSetting.Status = "✓"
-			return compare
-		}
-
-		if org.Organization.SamlIdentityProvider.Id != "" {
-			if org.Organization.SamlIdentityProvider.Id != ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id {
-				compare.TwoFactorAuthenticationSetting.Comment = "SAML Single Sign On is enabled at the Enterprise level and the Organization level. The Enterprise SAML Single Sign On provider will apply to the Organization."
-				compare.TwoFactorAuthenticationSetting.Status = "✗"
-				return compare
-			}
-		}
-
-		return compare
+	err = fetchRemainingEnterpriseIpAllowListEntries(client, ent, &query.Enterprise.OwnerInfo.IpAllowListEntries)
+	if err != nil {
+		return nil, err
 	}
+	// fmt.Println(query)
 
-	return compare
+	return query, err
 }
-
-// func createCSV(org *OrganizationGQLPolicies, ent *getEnterprisePolicies, compare *Compare) {
-// 	fmt.Println("Creating CSV")
-// 	csvWriter := createCSVFile()
-
-// 	fmt.Println(csvWriter)
-
-// 	csvWriter.Write([]string{"Policy", "Category", "Organization", "Enterprise", "Comment", "Status"})
-
-// 	csvWriter.Write([]string{"Allow Private Repository Forking", "Repository", org.MembersCanForkPrivateRepositories, ent.OwnerInfo.AllowPrivateRepositoryForkingSetting, compare.PrivateRepositoryForking})
-
-// 	csvWriter.Flush()
-// }
-
-// func createCSVFile() *csv.Writer {
-// 	csvFile, err := os.Create("output.csv")
-// 	if err != nil {
-// 		log.Fatal(err)
-// 	}
-// 	csvWriter := csv.NewWriter(csvFile)
-// 	return csvWriter
-// }
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// Roles of the users of an organization.
+const (
+	roleOwner               = "owner"
+	roleMember              = "member"
+	roleOutsideCollaborator = "outside collaborator"
+)
+
+// OrganizationUser is a member or an outside collaborator of an organization.
+type OrganizationUser struct {
+	Login string
+	Role  string
+}
+
+// getTwoFactorDisabledUsers lists the owners, members and outside collaborators of an organization
+// who have not enabled two factor authentication. Only organization owners can filter on it.
+func getTwoFactorDisabledUsers(org string) ([]OrganizationUser, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []OrganizationUser
+
+	for _, list := range []struct {
+		path string
+		role string
+	}{
+		{fmt.Sprintf("orgs/%s/members?filter=2fa_disabled&role=admin", org), roleOwner},
+		{fmt.Sprintf("orgs/%s/members?filter=2fa_disabled&role=member", org), roleMember},
+		{fmt.Sprintf("orgs/%s/outside_collaborators?filter=2fa_disabled", org), roleOutsideCollaborator},
+	} {
+		logins, err := getLogins(client, fmt.Sprintf("%s&per_page=%d", list.path, restPageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, login := range logins {
+			users = append(users, OrganizationUser{Login: login, Role: list.role})
+		}
+	}
+
+	return users, nil
+}
+
+// getLogins requests every page of a REST list of users and returns their logins.
+func getLogins(client api.RESTClient, path string) ([]string, error) {
+	var logins []string
+
+	err := getAllPages(client, path, func(body []byte) error {
+		var page []struct {
+			Login string
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, user := range page {
+			logins = append(logins, user.Login)
+		}
+
+		return nil
+	})
+
+	return logins, err
+}
+
+// twoFactorImpact predicts what happens to a user without two factor authentication once the
+// enterprise policy applies. Members keep their membership but cannot access the organization,
+// outside collaborators are removed.
+func twoFactorImpact(user OrganizationUser, ent *EnterprisePolicies) string {
+	if ent == nil {
+		return ""
+	}
+
+	if ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting != "ENABLED" {
+		return "no change"
+	}
+
+	if user.Role == roleOutsideCollaborator {
+		return "removed from the Organization"
+	}
+
+	return "loses access until two factor authentication is enabled"
+}
+
+// twoFactorInventory lists the users of an organization without two factor authentication. The
+// impact column is only filled in when an enterprise is given.
+func twoFactorInventory(org string, users []OrganizationUser, ent *EnterprisePolicies) Inventory {
+	inventory := Inventory{
+		Name:    "Users without two factor authentication: " + org,
+		Columns: []string{"Login", "Role", "After Transfer"},
+	}
+
+	for _, user := range users {
+		inventory.Rows = append(inventory.Rows, []string{user.Login, user.Role, twoFactorImpact(user, ent)})
+	}
+
+	return inventory
+}
+
+var twoFactorUsersRule = Rule{
+	ID:       "two-factor-authentication-users",
+	Policy:   "Users Without Two Factor Authentication",
+	Category: "account",
+	Severity: SeverityBlocker,
+	Inputs:   []string{"enterprise.TwoFactorRequiredSetting", "organization.members.2fa_disabled", "organization.outside_collaborators.2fa_disabled"},
+	Scopes:   []string{"read:org"},
+}
+
+// auditTwoFactorUsers lists the users of an organization without two factor authentication and
+// projects the enterprise two factor authentication policy onto them.
+func auditTwoFactorUsers(org string, ent *EnterprisePolicies) ([]Finding, []Inventory) {
+	users, err := getTwoFactorDisabledUsers(org)
+	if err != nil {
+		return []Finding{uncollectedFinding(twoFactorUsersRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(twoFactorUsersRule, compareTwoFactorUsers(users, ent))
+
+	return []Finding{finding}, []Inventory{twoFactorInventory(org, users, ent)}
+}
+
+// reconcileTwoFactorFindings stops the two factor authentication setting and the users without it from
+// counting the same problem twice. Once the users are collected, the setting finding is informational,
+// and it passes when every user already has two factor authentication.
+func reconcileTwoFactorFindings(findings []Finding) {
+	var users *Finding
+	for i := range findings {
+		if findings[i].RuleID == twoFactorUsersRule.ID && findings[i].Status != statusUnknown {
+			users = &findings[i]
+		}
+	}
+	if users == nil {
+		return
+	}
+
+	for i := range findings {
+		if findings[i].RuleID != "two-factor-authentication" || findings[i].Status != statusFail {
+			continue
+		}
+
+		findings[i].Severity = SeverityInfo
+		if users.Status == statusPass {
+			findings[i].Comment = "The Enterprise two factor authentication setting will apply to the Organization. Every member and outside collaborator already has two factor authentication enabled."
+			findings[i].Status = statusPass
+			findings[i].Remediation = ""
+		}
+	}
+}
+
+func compareTwoFactorUsers(users []OrganizationUser, ent *EnterprisePolicies) Finding {
+	var members, collaborators []string
+	for _, user := range users {
+		if user.Role == roleOutsideCollaborator {
+			collaborators = append(collaborators, user.Login)
+		} else {
+			members = append(members, user.Login)
+		}
+	}
+
+	setting := ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d members, %d outside collaborators without 2FA", len(members), len(collaborators)),
+		TargetValue:    setting,
+		EffectiveValue: twoFactorEffectiveValue(setting, members, collaborators),
+	}
+
+	if setting != "ENABLED" {
+		finding.Comment = "The Enterprise does not require two factor authentication."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if len(users) == 0 {
+		finding.Comment = "Every member and outside collaborator of the Organization has two factor authentication enabled."
+		finding.Status = statusPass
+		return finding
+	}
+
+	var problems []string
+	if len(members) > 0 {
+		problems = append(problems, fmt.Sprintf("The members %s will lose access until they enable two factor authentication.", strings.Join(members, ", ")))
+	}
+	if len(collaborators) > 0 {
+		problems = append(problems, fmt.Sprintf("The outside collaborators %s will be removed from the Organization.", strings.Join(collaborators, ", ")))
+	}
+
+	finding.Comment = strings.Join(problems, " ")
+	finding.Status = statusFail
+	finding.Remediation = "Contact these users and ask them to enable two factor authentication before the transfer."
+
+	return finding
+}
+
+func twoFactorEffectiveValue(setting string, members []string, collaborators []string) string {
+	if setting != "ENABLED" {
+		return "no change"
+	}
+
+	return fmt.Sprintf("%d members without access, %d outside collaborators removed", len(members), len(collaborators))
+}
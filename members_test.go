@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareTwoFactorUsers(t *testing.T) {
+	users := []OrganizationUser{
+		{Login: "monalisa", Role: roleOwner},
+		{Login: "hubot", Role: roleMember},
+		{Login: "contractor", Role: roleOutsideCollaborator},
+	}
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "NO_POLICY"
+
+	if finding := compareTwoFactorUsers(users, ent); finding.Status != statusPass {
+		t.Errorf("expected no impact without an Enterprise policy, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+
+	finding := compareTwoFactorUsers(users, ent)
+	want := "The members monalisa, hubot will lose access until they enable two factor authentication. The outside collaborators contractor will be removed from the Organization."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	rows := twoFactorInventory("octodemo", users, ent).Rows
+	if rows[2][2] != "removed from the Organization" || rows[0][2] != "loses access until two factor authentication is enabled" {
+		t.Errorf("unexpected impact rows %v", rows)
+	}
+
+	if rows := twoFactorInventory("octodemo", users, nil).Rows; rows[0][2] != "" {
+		t.Errorf("expected no impact without an Enterprise, got %v", rows[0])
+	}
+}
+
+func TestReconcileTwoFactorFindings(t *testing.T) {
+	org := new(OrganizationPolicies)
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+
+	var setting Finding
+	for _, finding := range runRules(org, ent) {
+		if finding.RuleID == "two-factor-authentication" {
+			setting = finding
+		}
+	}
+
+	users := []OrganizationUser{{Login: "hubot", Role: roleMember}}
+	findings := []Finding{setting, withRuleMetadata(twoFactorUsersRule, compareTwoFactorUsers(users, ent))}
+	reconcileTwoFactorFindings(findings)
+
+	if findings[0].Status != statusFail || findings[0].Severity != SeverityInfo || findings[1].Severity != SeverityBlocker {
+		t.Errorf("expected only the users finding to count, got %+v", findings)
+	}
+	if readiness := transferReadiness("octodemo", findings); readiness.Score != 75 {
+		t.Errorf("expected one blocker to be counted, got %+v", readiness)
+	}
+
+	findings = []Finding{setting, withRuleMetadata(twoFactorUsersRule, compareTwoFactorUsers(nil, ent))}
+	reconcileTwoFactorFindings(findings)
+
+	if findings[0].Status != statusPass {
+		t.Errorf("expected the setting to pass when every user has two factor authentication, got %+v", findings[0])
+	}
+
+	findings = []Finding{setting, uncollectedFinding(twoFactorUsersRule, errors.New("timeout"))}
+	reconcileTwoFactorFindings(findings)
+
+	if findings[0].Status != statusFail || findings[0].Severity != SeverityBlocker {
+		t.Errorf("expected the setting to keep its severity when the users are not collected, got %+v", findings[0])
+	}
+}
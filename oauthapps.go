@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// The audit log actions that record OAuth app access restrictions. There is no API for the
+// restriction setting itself, so it is rebuilt from these events.
+var oauthAppAuditActions = []string{
+	"org.enable_oauth_app_restrictions",
+	"org.disable_oauth_app_restrictions",
+	"org.oauth_app_access_approved",
+	"org.oauth_app_access_denied",
+	"org.oauth_app_access_requested",
+}
+
+// AuditLogEvent is an event of the REST audit log. Timestamp is in milliseconds since the epoch.
+type AuditLogEvent struct {
+	Action                 string
+	Timestamp              int64 `json:"@timestamp"`
+	Org                    string
+	Oauth_application_name string
+}
+
+// OAuthAppAccess is the OAuth app access restriction state of an organization, or of the organizations
+// of an enterprise. RestrictionsEnabled is "" when the audit log does not tell. An app is in at most
+// one of Approved, Denied and Requested.
+type OAuthAppAccess struct {
+	RestrictionsEnabled string
+	Approved            []string
+	Denied              []string
+	Requested           []string
+}
+
+func getOrganizationOAuthAppAccess(org string) (*OAuthAppAccess, error) {
+	events, err := getOAuthAppAuditEvents(fmt.Sprintf("orgs/%s", org))
+	if err != nil {
+		return nil, err
+	}
+
+	return replayOAuthAppAccess(events), nil
+}
+
+// getEnterpriseOAuthAppAccess replays the OAuth app events of every organization of an enterprise. The
+// restrictions count as enabled when every organization that changed them last enabled them. This only
+// describes the other organizations within the audit log retention, it is not an enterprise policy.
+func getEnterpriseOAuthAppAccess(ent string) (*OAuthAppAccess, error) {
+	events, err := getOAuthAppAuditEvents(fmt.Sprintf("enterprises/%s", ent))
+	if err != nil {
+		return nil, err
+	}
+
+	byOrg := make(map[string][]AuditLogEvent)
+	for _, event := range events {
+		byOrg[event.Org] = append(byOrg[event.Org], event)
+	}
+
+	access := replayOAuthAppAccess(events)
+	access.RestrictionsEnabled = ""
+
+	for _, orgEvents := range byOrg {
+		switch replayOAuthAppAccess(orgEvents).RestrictionsEnabled {
+		case "false":
+			access.RestrictionsEnabled = "false"
+		case "true":
+			if access.RestrictionsEnabled == "" {
+				access.RestrictionsEnabled = "true"
+			}
+		}
+	}
+
+	return access, nil
+}
+
+// getOAuthAppAuditEvents requests the OAuth app events under an orgs/ or enterprises/ REST path.
+func getOAuthAppAuditEvents(owner string) ([]AuditLogEvent, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []AuditLogEvent
+	for _, action := range oauthAppAuditActions {
+		actionEvents, err := getAuditLogEvents(client, owner, "action:"+action)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, actionEvents...)
+	}
+
+	return events, nil
+}
+
+// getAuditLogEvents requests every audit log event matching a search phrase.
+func getAuditLogEvents(client api.RESTClient, owner string, phrase string) ([]AuditLogEvent, error) {
+	var events []AuditLogEvent
+
+	path := fmt.Sprintf("%s/audit-log?phrase=%s&per_page=%d", owner, url.QueryEscape(phrase), restPageSize)
+
+	err := getAllPages(client, path, func(body []byte) error {
+		var page []AuditLogEvent
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		events = append(events, page...)
+
+		return nil
+	})
+
+	return events, err
+}
+
+// replayOAuthAppAccess replays OAuth app events from the oldest to the newest.
+func replayOAuthAppAccess(events []AuditLogEvent) *OAuthAppAccess {
+	events = append([]AuditLogEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	access := new(OAuthAppAccess)
+	state := make(map[string]string)
+	var apps []string
+
+	for _, event := range events {
+		switch event.Action {
+		case "org.enable_oauth_app_restrictions":
+			access.RestrictionsEnabled = "true"
+		case "org.disable_oauth_app_restrictions":
+			access.RestrictionsEnabled = "false"
+		default:
+			name := event.Oauth_application_name
+			if name == "" {
+				continue
+			}
+			if _, ok := state[name]; !ok {
+				apps = append(apps, name)
+			}
+
+			switch event.Action {
+			case "org.oauth_app_access_approved":
+				state[name] = "approved"
+			case "org.oauth_app_access_denied":
+				state[name] = "denied"
+			case "org.oauth_app_access_requested":
+				// a new request does not undo an earlier decision
+				if state[name] == "" {
+					state[name] = "requested"
+				}
+			}
+
+			// apps are only approved or denied while the restrictions are enabled
+			if access.RestrictionsEnabled == "" && state[name] != "requested" {
+				access.RestrictionsEnabled = "true"
+			}
+		}
+	}
+
+	sort.Strings(apps)
+	for _, name := range apps {
+		switch state[name] {
+		case "approved":
+			access.Approved = append(access.Approved, name)
+		case "denied":
+			access.Denied = append(access.Denied, name)
+		case "requested":
+			access.Requested = append(access.Requested, name)
+		}
+	}
+
+	return access
+}
+
+// oauthAppsInventory lists the OAuth apps an organization or enterprise approved, denied or was asked for.
+func oauthAppsInventory(owner string, access *OAuthAppAccess) Inventory {
+	inventory := Inventory{
+		Name:    fmt.Sprintf("OAuth app access: %s (restrictions enabled: %s)", owner, valueOrUnknown(access.RestrictionsEnabled)),
+		Columns: []string{"OAuth App", "Access"},
+	}
+
+	for _, group := range []struct {
+		access string
+		apps   []string
+	}{
+		{"approved", access.Approved},
+		{"denied", access.Denied},
+		{"requested", access.Requested},
+	} {
+		for _, app := range group.apps {
+			inventory.Rows = append(inventory.Rows, []string{app, group.access})
+		}
+	}
+
+	return inventory
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
+
+var oauthAppRestrictionsRule = Rule{
+	ID:       "oauth-app-restrictions",
+	Policy:   "OAuth App Access Restrictions",
+	Category: "integration",
+	Severity: SeverityHigh,
+	Inputs:   []string{"enterprise.auditLog.oauthApps", "organization.auditLog.oauthApps"},
+	Scopes:   []string{"read:audit_log"},
+}
+
+// auditOAuthApps rebuilds the OAuth app access restrictions of an organization and an enterprise from
+// their audit logs and compares them.
+func auditOAuthApps(org string, ent string) ([]Finding, []Inventory) {
+	orgAccess, err := getOrganizationOAuthAppAccess(org)
+	if err != nil {
+		return []Finding{uncollectedFinding(oauthAppRestrictionsRule, err)}, nil
+	}
+
+	entAccess, err := getEnterpriseOAuthAppAccess(ent)
+	if err != nil {
+		return []Finding{uncollectedFinding(oauthAppRestrictionsRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(oauthAppRestrictionsRule, compareOAuthAppAccess(orgAccess, entAccess))
+
+	return []Finding{finding}, []Inventory{
+		oauthAppsInventory(org, orgAccess),
+		oauthAppsInventory(ent, entAccess),
+	}
+}
+
+func compareOAuthAppAccess(org *OAuthAppAccess, ent *OAuthAppAccess) Finding {
+	finding := Finding{
+		SourceValue:    valueOrUnknown(org.RestrictionsEnabled),
+		TargetValue:    valueOrUnknown(ent.RestrictionsEnabled),
+		EffectiveValue: valueOrUnknown(org.RestrictionsEnabled),
+	}
+
+	if ent.RestrictionsEnabled != "true" {
+		finding.Comment = "The Enterprise's Organizations do not all restrict OAuth app access. The Organization setting will be kept."
+		finding.Status = statusPass
+		return finding
+	}
+
+	// a restriction both audit logs show is compared, what can only be inferred from them is informational
+	switch org.RestrictionsEnabled {
+	case "false":
+		finding.EffectiveValue = "true"
+		finding.Comment = "The Enterprise's Organizations restrict OAuth app access according to their audit logs, the Organization does not. Developers may lose access through OAuth apps nobody approved once the Organization follows the Enterprise."
+		finding.Status = statusFail
+		finding.Remediation = "List the OAuth apps developers rely on and have an owner approve them, or agree with the Enterprise owners that the Organization stays unrestricted, before the transfer."
+		return finding
+	case "":
+		finding.Comment = "The Enterprise's Organizations restrict OAuth app access. The Organization's audit log does not show whether it restricts OAuth apps."
+		finding.Severity = SeverityInfo
+		finding.Status = statusUnknown
+		finding.Remediation = "Check the OAuth app policy in the Organization settings."
+		return finding
+	}
+
+	// apps the Organization allows that the Enterprise's Organizations have denied
+	denied := make(map[string]bool)
+	for _, app := range ent.Denied {
+		denied[app] = true
+	}
+
+	var cutOff []string
+	for _, app := range org.Approved {
+		if denied[app] {
+			cutOff = append(cutOff, app)
+		}
+	}
+
+	if len(cutOff) == 0 {
+		finding.Comment = "The Organization already restricts OAuth app access, and the Enterprise's Organizations have not denied any app it approved."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The Organization approved %s, which other Organizations of the Enterprise denied according to their audit logs.", strings.Join(cutOff, ", "))
+	finding.Severity = SeverityInfo
+	finding.Status = statusUnknown
+	finding.Remediation = fmt.Sprintf("Agree with the Enterprise owners whether %s may keep access.", strings.Join(cutOff, ", "))
+
+	return finding
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplayOAuthAppAccess(t *testing.T) {
+	events := []AuditLogEvent{
+		{Action: "org.oauth_app_access_approved", Timestamp: 3, Oauth_application_name: "ci-dashboard"},
+		{Action: "org.oauth_app_access_requested", Timestamp: 1, Oauth_application_name: "ci-dashboard"},
+		{Action: "org.oauth_app_access_requested", Timestamp: 4, Oauth_application_name: "ci-dashboard"},
+		{Action: "org.oauth_app_access_denied", Timestamp: 5, Oauth_application_name: "gist-sync"},
+		{Action: "org.oauth_app_access_requested", Timestamp: 6, Oauth_application_name: "wiki-export"},
+	}
+
+	access := replayOAuthAppAccess(events)
+
+	want := &OAuthAppAccess{
+		RestrictionsEnabled: "true",
+		Approved:            []string{"ci-dashboard"},
+		Denied:              []string{"gist-sync"},
+		Requested:           []string{"wiki-export"},
+	}
+	if !reflect.DeepEqual(access, want) {
+		t.Errorf("expected %+v, got %+v", want, access)
+	}
+
+	events = append(events, AuditLogEvent{Action: "org.disable_oauth_app_restrictions", Timestamp: 7})
+	if access := replayOAuthAppAccess(events); access.RestrictionsEnabled != "false" {
+		t.Errorf("expected the restrictions to be disabled by the latest event, got %q", access.RestrictionsEnabled)
+	}
+
+	if access := replayOAuthAppAccess(nil); access.RestrictionsEnabled != "" {
+		t.Errorf("expected an empty audit log to leave the restrictions unknown, got %q", access.RestrictionsEnabled)
+	}
+}
+
+func TestCompareOAuthAppAccess(t *testing.T) {
+	ent := &OAuthAppAccess{RestrictionsEnabled: "true", Denied: []string{"gist-sync"}}
+
+	tests := []struct {
+		org      *OAuthAppAccess
+		want     string
+		severity Severity
+	}{
+		{&OAuthAppAccess{RestrictionsEnabled: "false"}, statusFail, SeverityHigh},
+		{&OAuthAppAccess{}, statusUnknown, SeverityInfo},
+		{&OAuthAppAccess{RestrictionsEnabled: "true", Approved: []string{"ci-dashboard"}}, statusPass, SeverityInfo},
+		{&OAuthAppAccess{RestrictionsEnabled: "true", Approved: []string{"gist-sync"}}, statusUnknown, SeverityInfo},
+	}
+
+	for _, test := range tests {
+		finding := withRuleMetadata(oauthAppRestrictionsRule, compareOAuthAppAccess(test.org, ent))
+		if finding.Status != test.want || finding.Severity != test.severity {
+			t.Errorf("%+v: expected a %s %s, got %+v", test.org, test.severity, test.want, finding)
+		}
+	}
+
+	if finding := compareOAuthAppAccess(&OAuthAppAccess{RestrictionsEnabled: "false"}, &OAuthAppAccess{}); finding.Status != statusPass {
+		t.Errorf("expected no finding when the Enterprise does not restrict OAuth apps, got %+v", finding)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jung-kurt/gofpdf"
 )
@@ -37,6 +38,145 @@ func CreatePDF() {
 	fmt.Println(err)
 }
 
+// pdfStatusColors is the row fill color for each finding status.
+var pdfStatusColors = map[string][3]int{
+	statusPass:    {214, 239, 214},
+	statusFail:    {248, 206, 204},
+	statusUnknown: {255, 242, 204},
+}
+
+// pdfColumnWidths are the widths in mm of the reportHeader columns on a landscape A4 page.
+var pdfColumnWidths = []float64{32, 20, 16, 24, 24, 24, 14, 64, 59}
+
+// writePDFReport renders a report as a landscape table, one row per finding, colored by status.
+func writePDFReport(report Report, path string) error {
+	const lineHeight = 4.5
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetFont("Arial", "", 8)
+	pdf.SetAutoPageBreak(false, 10)
+
+	_, pageHeight := pdf.GetPageSize()
+	_, topMargin, _, bottomMargin := pdf.GetMargins()
+
+	// row draws a table row. A row that does not fit on the rest of the page starts on a new one, and a
+	// row taller than a whole page is split, its cells continuing on the following pages.
+	row := func(widths []float64, cells []string, fill [3]int) {
+		lines := make([][]string, len(cells))
+		count := 1
+		for i, cell := range cells {
+			for _, line := range pdf.SplitLines([]byte(tr(cell)), widths[i]-2) {
+				lines[i] = append(lines[i], string(line))
+			}
+			if len(lines[i]) > count {
+				count = len(lines[i])
+			}
+		}
+
+		pdf.SetFillColor(fill[0], fill[1], fill[2])
+		for start := 0; start < count; {
+			fit := int((pageHeight - bottomMargin - pdf.GetY()) / lineHeight)
+			if fit < count-start && pdf.GetY() > topMargin {
+				pdf.AddPage()
+				fit = int((pageHeight - bottomMargin - pdf.GetY()) / lineHeight)
+			}
+
+			end := start + fit
+			if end > count {
+				end = count
+			}
+			height := float64(end-start) * lineHeight
+
+			for i := range cells {
+				var text string
+				if start < len(lines[i]) {
+					last := end
+					if last > len(lines[i]) {
+						last = len(lines[i])
+					}
+					text = strings.Join(lines[i][start:last], "\n")
+				}
+
+				x, y := pdf.GetXY()
+				pdf.Rect(x, y, widths[i], height, "FD")
+				pdf.MultiCell(widths[i], lineHeight, text, "", "L", false)
+				pdf.SetXY(x+widths[i], y)
+			}
+			pdf.Ln(height)
+
+			start = end
+		}
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 14)
+	pdf.Cell(0, 10, tr(fmt.Sprintf("Transfer audit: %s and %s", report.Source, report.Target)))
+	pdf.Ln(12)
+
+	pdf.SetFont("Arial", "B", 8)
+	row(pdfColumnWidths, reportHeader(report), [3]int{220, 220, 220})
+
+	pdf.SetFont("Arial", "", 8)
+	for _, finding := range report.Findings {
+		fill, ok := pdfStatusColors[finding.Status]
+		if !ok {
+			fill = [3]int{255, 255, 255}
+		}
+
+		row(pdfColumnWidths, reportRow(finding), fill)
+	}
+
+	if len(report.Effective) > 0 {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(0, 10, tr(fmt.Sprintf("Effective settings of %s after the transfer", report.Source)))
+		pdf.Ln(12)
+
+		widths := []float64{80, 60, 60, 77}
+
+		pdf.SetFont("Arial", "B", 8)
+		row(widths, []string{"Setting", "Today", "After Transfer", "Enforced By"}, [3]int{220, 220, 220})
+
+		pdf.SetFont("Arial", "", 8)
+		for _, setting := range report.Effective {
+			fill := [3]int{255, 255, 255}
+			if setting.Effective != setting.Current {
+				fill = pdfStatusColors[statusUnknown]
+			}
+
+			row(widths, []string{setting.Key, setting.Current, setting.Effective, setting.EnforcedBy}, fill)
+		}
+	}
+
+	// each inventory gets its own page, its columns sharing the page width evenly
+	for _, inventory := range report.Inventories {
+		if len(inventory.Columns) == 0 {
+			continue
+		}
+
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(0, 10, tr(inventory.Name))
+		pdf.Ln(12)
+
+		widths := make([]float64, len(inventory.Columns))
+		for i := range widths {
+			widths[i] = 277 / float64(len(widths))
+		}
+
+		pdf.SetFont("Arial", "B", 8)
+		row(widths, inventory.Columns, [3]int{220, 220, 220})
+
+		pdf.SetFont("Arial", "", 8)
+		for _, cells := range inventory.Rows {
+			row(widths, cells, [3]int{255, 255, 255})
+		}
+	}
+
+	return pdf.OutputFileAndClose(path)
+}
+
 // // ExampleFpdf_CellFormat_tables demonstrates various table styles.
 // func ExampleFpdf_CellFormat_tables() {
 // 	pdf := gofpdf.New("P", "mm", "A4", "")
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Report is a set of findings together with the names of the source and target that were compared.
+// Every output format is rendered from a Report. Effective is only set when an organization is
+// compared with the enterprise it will join.
+type Report struct {
+	Source      string
+	Target      string
+	Findings    []Finding
+	Effective   []EffectiveSetting `json:",omitempty"`
+	Inventories []Inventory        `json:",omitempty"`
+}
+
+// Inventory is a named table of collected items, such as the IP allow list entries of an
+// organization, rendered after the findings of a report.
+type Inventory struct {
+	Name    string
+	Columns []string
+	Rows    [][]string
+}
+
+var statusNames = map[string]string{
+	statusPass:    "pass",
+	statusFail:    "fail",
+	statusUnknown: "unknown",
+}
+
+// statusName returns a plain text name for a finding status, for output formats that cannot render ✓ and ✗.
+func statusName(status string) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+
+	return status
+}
+
+// reportHeader returns the column names shared by the CSV and PDF renderers.
+func reportHeader(report Report) []string {
+	return []string{"Policy", "Category", "Severity", report.Source, report.Target, "Effective", "Status", "Comment", "Remediation"}
+}
+
+// reportRow returns the columns of a finding in the order of reportHeader.
+func reportRow(finding Finding) []string {
+	return []string{
+		finding.Policy,
+		finding.Category,
+		finding.Severity.String(),
+		finding.SourceValue,
+		finding.TargetValue,
+		finding.EffectiveValue,
+		statusName(finding.Status),
+		finding.Comment,
+		finding.Remediation,
+	}
+}
+
+// writeReport renders a report in the given format. Table, CSV and JSON reports are written to output,
+// or to stdout when output is empty. PDF reports are always written to a file.
+func writeReport(report Report, format string, output string) error {
+	if format == "pdf" {
+		if output == "" {
+			output = "transfer-audit.pdf"
+		}
+
+		return writePDFReport(report, output)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("could not create %s: %w", output, err)
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	switch format {
+	case "table":
+		if err := tablePrintFindings(w, report); err != nil {
+			return err
+		}
+
+		if len(report.Effective) > 0 {
+			fmt.Fprintln(w)
+
+			if err := tablePrintEffectiveSettings(w, report); err != nil {
+				return err
+			}
+		}
+
+		for _, inventory := range report.Inventories {
+			fmt.Fprintln(w)
+
+			if err := tablePrintInventory(w, inventory); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "csv":
+		return writeCSVReport(w, report)
+	case "json":
+		return writeJSONReport(w, report)
+	}
+
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func writeCSVReport(w io.Writer, report Report) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write(reportHeader(report)); err != nil {
+		return err
+	}
+
+	for _, finding := range report.Findings {
+		if err := csvWriter.Write(reportRow(finding)); err != nil {
+			return err
+		}
+	}
+
+	// inventories follow the findings as their own sections, each headed by its name and columns
+	for _, inventory := range report.Inventories {
+		if err := csvWriter.Write(nil); err != nil {
+			return err
+		}
+		if err := csvWriter.Write([]string{inventory.Name}); err != nil {
+			return err
+		}
+		if err := csvWriter.Write(inventory.Columns); err != nil {
+			return err
+		}
+		if err := csvWriter.WriteAll(inventory.Rows); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+func writeJSONReport(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testReport() Report {
+	return Report{
+		Source: "octodemo",
+		Target: "github",
+		Findings: []Finding{
+			{
+				RuleID:         "two-factor-authentication",
+				Policy:         "Two Factor Authentication Setting",
+				Category:       "account",
+				Severity:       SeverityBlocker,
+				SourceValue:    "false",
+				TargetValue:    "ENABLED",
+				EffectiveValue: "true",
+				Comment:        "The Enterprise two factor authentication setting will apply to the Organization.",
+				Remediation:    "Ask members to enable two factor authentication.",
+				Status:         statusFail,
+			},
+			{
+				RuleID:      "private-repository-forking",
+				Policy:      "Private Repository Forking",
+				Category:    "repository",
+				Severity:    SeverityInfo,
+				SourceValue: "true",
+				TargetValue: "NO_POLICY",
+				Comment:     "There is no Enterprise policy.",
+				Status:      statusPass,
+			},
+		},
+	}
+}
+
+func TestWriteCSVReport(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writeCSVReport(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+	if records[0][3] != "octodemo" || records[0][4] != "github" {
+		t.Errorf("expected source and target names in the header, got %v", records[0])
+	}
+	if records[1][2] != "blocker" || records[1][5] != "true" || records[1][6] != "fail" {
+		t.Errorf("unexpected row %v", records[1])
+	}
+}
+
+func TestWriteCSVReportInventories(t *testing.T) {
+	var buf bytes.Buffer
+
+	report := testReport()
+	report.Inventories = []Inventory{ipAllowListInventory("octodemo", testIpAllowListEntries("192.0.2.0/24"))}
+
+	if err := writeCSVReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := csv.NewReader(&buf)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the blank separator line is skipped by the reader
+	if len(records) != 6 {
+		t.Fatalf("expected the findings followed by an inventory section, got %v", records)
+	}
+	if records[3][0] != "IP allow list: octodemo" || records[5][1] != "192.0.2.0/24" {
+		t.Errorf("unexpected inventory section %v", records[3:])
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writeJSONReport(&buf, testReport()); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Findings[0].Severity != SeverityBlocker {
+		t.Errorf("severity did not round trip: %v", decoded.Findings[0].Severity)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"Severity": "blocker"`)) {
+		t.Errorf("expected severity to be written by name:\n%s", buf.String())
+	}
+}
+
+func TestWritePDFReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+
+	if err := writePDFReport(testReport(), path); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Fatalf("expected a PDF to be written to %s: %v", path, err)
+	}
+}
+
+func TestWritePDFReportSplitsLongRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+
+	report := testReport()
+	report.Inventories = nil
+	report.Findings[0].Comment = strings.Repeat("Every line of this comment must make it into the PDF. ", 400)
+
+	if err := writePDFReport(report, path); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pages := bytes.Count(body, []byte("/Type /Page\n")); pages < 3 {
+		t.Errorf("expected the long row to continue over several pages, got %d pages", pages)
+	}
+}
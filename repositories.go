@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// Repository is a repository of an organization as returned by the REST API. Size is in kilobytes.
+// Parent is only returned when a single repository is requested, Security_and_analysis only to admins.
+type Repository struct {
+	Name        string
+	Full_name   string
+	Owner       RepositoryOwner
+	Visibility  string
+	Private     bool
+	Fork        bool
+	Archived    bool
+	Size        int
+	Forks_count int
+	Topics      []string
+	Parent      *Repository
+
+	Security_and_analysis *SecurityAndAnalysis
+}
+
+// RepositoryOwner is the user or organization that owns a repository. Type is "User" or "Organization".
+type RepositoryOwner struct {
+	Login string
+	Type  string
+}
+
+// OrganizationRepositories are the repositories of an organization, with the parents of its forks, and
+// the forks of its private and internal repositories wherever they live.
+type OrganizationRepositories struct {
+	Repositories []Repository
+	PrivateForks []Repository
+}
+
+// getOrganizationRepositories lists every repository of an organization.
+func getOrganizationRepositories(client api.RESTClient, org string) ([]Repository, error) {
+	var repositories []Repository
+
+	err := getAllPages(client, fmt.Sprintf("orgs/%s/repos?type=all&per_page=%d", org, restPageSize), func(body []byte) error {
+		var page []Repository
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		repositories = append(repositories, page...)
+
+		return nil
+	})
+
+	return repositories, err
+}
+
+// listOrganizationRepositories lists the repositories of an organization, once for every collector that covers them.
+func listOrganizationRepositories(org string) ([]Repository, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return getOrganizationRepositories(client, org)
+}
+
+// getOrganizationRepositoryInventory looks up the parent of each fork among the repositories of an
+// organization and collects the forks of its private and internal repositories. Forks of repositories owned
+// elsewhere follow their parent's owner's forking policy, so they are not collected as private forks.
+func getOrganizationRepositoryInventory(org string, repositories []Repository) (*OrganizationRepositories, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the parents of forks are filled in on a copy, the list is shared with the other audits
+	repositories = append([]Repository(nil), repositories...)
+	inventory := &OrganizationRepositories{Repositories: repositories}
+
+	for i, repository := range repositories {
+		if !repository.Fork {
+			continue
+		}
+
+		var details Repository
+		if err := client.Get("repos/"+repository.Full_name, &details); err != nil {
+			return nil, err
+		}
+		repositories[i].Parent = details.Parent
+	}
+
+	for _, repository := range repositories {
+		if !repository.Private || repository.Forks_count == 0 {
+			continue
+		}
+
+		forks, err := getRepositoryForks(client, repository)
+		if err != nil {
+			return nil, err
+		}
+
+		inventory.PrivateForks = append(inventory.PrivateForks, forks...)
+	}
+
+	return inventory, nil
+}
+
+// getRepositoryForks lists the forks of a repository with their parent set to it.
+func getRepositoryForks(client api.RESTClient, parent Repository) ([]Repository, error) {
+	var forks []Repository
+
+	err := getAllPages(client, fmt.Sprintf("repos/%s/forks?per_page=%d", parent.Full_name, restPageSize), func(body []byte) error {
+		var page []Repository
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, fork := range page {
+			fork.Parent = &parent
+			forks = append(forks, fork)
+		}
+
+		return nil
+	})
+
+	return forks, err
+}
+
+// privateForkAllowed tells whether the enterprise forking policy lets a fork of a private or internal
+// repository of org live under the fork's owner. Enterprise membership of other organizations is not
+// collected, so any organization counts as an enterprise organization.
+func privateForkAllowed(fork Repository, org string, ent *EnterprisePolicies) bool {
+	switch ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting {
+	case "DISABLED":
+		return false
+	case "ENABLED":
+	default:
+		return true
+	}
+
+	sameOrganization := strings.EqualFold(fork.Owner.Login, org)
+	userAccount := fork.Owner.Type == "User"
+
+	switch ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSettingPolicyValue {
+	case "SAME_ORGANIZATION":
+		return sameOrganization
+	case "SAME_ORGANIZATION_USER_ACCOUNTS":
+		return sameOrganization || userAccount
+	case "ENTERPRISE_ORGANIZATIONS":
+		return !userAccount
+	case "USER_ACCOUNTS":
+		return userAccount
+	}
+
+	return true
+}
+
+// privateForkImpact predicts how the enterprise forking policy affects a fork of a private or internal repository.
+func privateForkImpact(fork Repository, org string, ent *EnterprisePolicies) string {
+	if ent == nil {
+		return ""
+	}
+
+	if privateForkAllowed(fork, org, ent) {
+		return "no change"
+	}
+
+	if ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting == "DISABLED" {
+		return "private forking disabled"
+	}
+
+	return "fork owner not allowed by " + ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSettingPolicyValue
+}
+
+// isPrivateFork tells whether a repository is a fork of a private or internal repository of org. Forks of
+// repositories owned elsewhere are governed by the forking policy of that owner.
+func isPrivateFork(repository Repository, org string) bool {
+	return repository.Parent != nil && repository.Parent.Private && strings.EqualFold(repository.Parent.Owner.Login, org)
+}
+
+// repositoryImpact predicts how the enterprise forking and repository creation policies affect a repository.
+func repositoryImpact(repository Repository, affectedForks map[string]int, org string, ent *EnterprisePolicies) string {
+	if ent == nil {
+		return ""
+	}
+
+	var impacts []string
+	if count := affectedForks[repository.Full_name]; count > 0 {
+		impacts = append(impacts, fmt.Sprintf("%d forks affected", count))
+	}
+	if isPrivateFork(repository, org) && !privateForkAllowed(repository, org, ent) {
+		impacts = append(impacts, "private fork affected")
+	}
+
+	setting := ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting
+	if !noEnterprisePolicy(setting) && !enterpriseRepositoryCreation(setting)[repository.Visibility] {
+		impacts = append(impacts, fmt.Sprintf("members can no longer create %s repositories", repository.Visibility))
+	}
+
+	if len(impacts) == 0 {
+		return "no change"
+	}
+
+	return strings.Join(impacts, ", ")
+}
+
+// affectedPrivateForks lists the forks of private and internal repositories the enterprise forking policy does not allow.
+func affectedPrivateForks(repositories *OrganizationRepositories, org string, ent *EnterprisePolicies) []Repository {
+	var affected []Repository
+	for _, fork := range repositories.PrivateForks {
+		if !privateForkAllowed(fork, org, ent) {
+			affected = append(affected, fork)
+		}
+	}
+
+	return affected
+}
+
+// repositoriesInventory lists the repositories of an organization. The impact column is only filled in
+// when an enterprise is given.
+func repositoriesInventory(org string, repositories *OrganizationRepositories, ent *EnterprisePolicies) Inventory {
+	inventory := Inventory{
+		Name:    "Repositories: " + org,
+		Columns: []string{"Repository", "Visibility", "Fork", "Parent", "Archived", "Size (KB)", "After Transfer"},
+	}
+
+	affectedForks := make(map[string]int)
+	if ent != nil {
+		for _, fork := range affectedPrivateForks(repositories, org, ent) {
+			affectedForks[fork.Parent.Full_name]++
+		}
+	}
+
+	for _, repository := range repositories.Repositories {
+		var parent string
+		if repository.Parent != nil {
+			parent = repository.Parent.Full_name
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			repository.Full_name,
+			repository.Visibility,
+			strconv.FormatBool(repository.Fork),
+			parent,
+			strconv.FormatBool(repository.Archived),
+			strconv.Itoa(repository.Size),
+			repositoryImpact(repository, affectedForks, org, ent),
+		})
+	}
+
+	return inventory
+}
+
+// privateForksInventory lists the forks of the private and internal repositories of an organization.
+func privateForksInventory(org string, repositories *OrganizationRepositories, ent *EnterprisePolicies) Inventory {
+	inventory := Inventory{
+		Name:    "Private forks: " + org,
+		Columns: []string{"Fork", "Owner Type", "Parent", "Parent Visibility", "After Transfer"},
+	}
+
+	for _, fork := range repositories.PrivateForks {
+		inventory.Rows = append(inventory.Rows, []string{
+			fork.Full_name,
+			fork.Owner.Type,
+			fork.Parent.Full_name,
+			fork.Parent.Visibility,
+			privateForkImpact(fork, org, ent),
+		})
+	}
+
+	return inventory
+}
+
+var privateForksRule = Rule{
+	ID:       "private-repository-forks",
+	Policy:   "Existing Private Forks",
+	Category: "repository",
+	Severity: SeverityHigh,
+	Inputs:   []string{"enterprise.AllowPrivateRepositoryForkingSetting", "enterprise.AllowPrivateRepositoryForkingSettingPolicyValue", "organization.repositories", "organization.repositories.forks"},
+	Scopes:   []string{"repo"},
+}
+
+// auditRepositories lists the repositories of an organization and the forks of its private repositories,
+// and checks the forks against the enterprise forking policy.
+func auditRepositories(org string, repositoryList []Repository, ent *EnterprisePolicies) ([]Finding, []Inventory) {
+	repositories, err := getOrganizationRepositoryInventory(org, repositoryList)
+	if err != nil {
+		return []Finding{uncollectedFinding(privateForksRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(privateForksRule, comparePrivateForks(repositories, org, ent))
+
+	return []Finding{finding}, []Inventory{
+		repositoriesInventory(org, repositories, ent),
+		privateForksInventory(org, repositories, ent),
+	}
+}
+
+// uncollectedRepositoryFindings reports every check over the repositories of an organization as unknown
+// when the repositories could not be listed.
+func uncollectedRepositoryFindings(err error) []Finding {
+	var findings []Finding
+	for _, rule := range []Rule{
+		collaboratorInvitationsRule, privateForksRule, internalRepositoryExposureRule, sensitiveInternalRepositoriesRule,
+		webhookDeliveriesRule, secretsVisibilityRule, securityDefaultsRule, advancedSecurityLicensesRule,
+	} {
+		findings = append(findings, uncollectedFinding(rule, err))
+	}
+
+	return findings
+}
+
+func comparePrivateForks(repositories *OrganizationRepositories, org string, ent *EnterprisePolicies) Finding {
+	setting := ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting
+	if policyValue := ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSettingPolicyValue; setting == "ENABLED" && policyValue != "" {
+		setting = fmt.Sprintf("%s (%s)", setting, policyValue)
+	}
+
+	affected := affectedPrivateForks(repositories, org, ent)
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d private forks", len(repositories.PrivateForks)),
+		TargetValue:    setting,
+		EffectiveValue: fmt.Sprintf("%d private forks affected", len(affected)),
+	}
+
+	if noEnterprisePolicy(ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting) {
+		finding.Comment = "There is no Enterprise forking policy. Existing forks are not affected."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if len(affected) == 0 {
+		finding.Comment = "No existing fork of a private or internal repository conflicts with the Enterprise forking policy."
+		finding.Status = statusPass
+		return finding
+	}
+
+	var names []string
+	for _, fork := range affected {
+		names = append(names, fmt.Sprintf("%s (of %s)", fork.Full_name, fork.Parent.Full_name))
+	}
+
+	finding.Comment = fmt.Sprintf("The Enterprise forking policy does not allow the forks %s. They will be affected by the transfer.", strings.Join(names, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Merge the work in these forks back into their parent repositories, or agree an exception with the Enterprise owners, before the transfer."
+
+	return finding
+}
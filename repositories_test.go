@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestComparePrivateForks(t *testing.T) {
+	api := Repository{Full_name: "octodemo/api", Owner: RepositoryOwner{"octodemo", "Organization"}, Visibility: "private", Private: true, Forks_count: 2}
+	docs := Repository{Full_name: "octodemo/docs", Visibility: "public"}
+	sdk := Repository{Full_name: "vendor/sdk", Owner: RepositoryOwner{"vendor", "Organization"}, Visibility: "private", Private: true}
+	repositories := &OrganizationRepositories{
+		Repositories: []Repository{
+			api,
+			docs,
+			{Full_name: "octodemo/api-fork", Visibility: "private", Private: true, Fork: true, Owner: RepositoryOwner{"octodemo", "Organization"}, Parent: &api},
+			{Full_name: "octodemo/sdk", Visibility: "private", Private: true, Fork: true, Owner: RepositoryOwner{"octodemo", "Organization"}, Parent: &sdk},
+		},
+		PrivateForks: []Repository{
+			{Full_name: "octodemo/api-fork", Owner: RepositoryOwner{"octodemo", "Organization"}, Parent: &api},
+			{Full_name: "hubot/api", Owner: RepositoryOwner{"hubot", "User"}, Parent: &api},
+		},
+	}
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting = "NO_POLICY"
+
+	if finding := comparePrivateForks(repositories, "octodemo", ent); finding.Status != statusPass {
+		t.Errorf("expected no impact without an Enterprise policy, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting = "ENABLED"
+	ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSettingPolicyValue = "SAME_ORGANIZATION"
+
+	finding := comparePrivateForks(repositories, "octodemo", ent)
+	want := "The Enterprise forking policy does not allow the forks hubot/api (of octodemo/api). They will be affected by the transfer."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting = "DISABLED"
+	ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting = "PRIVATE"
+
+	if finding := comparePrivateForks(repositories, "octodemo", ent); finding.EffectiveValue != "2 private forks affected" {
+		t.Errorf("expected both forks to be affected, got %+v", finding)
+	}
+
+	rows := repositoriesInventory("octodemo", repositories, ent).Rows
+	impacts := []string{"2 forks affected", "members can no longer create public repositories", "private fork affected", "no change"}
+	for i, impact := range impacts {
+		if rows[i][6] != impact {
+			t.Errorf("row %d: expected %q, got %v", i, impact, rows[i])
+		}
+	}
+
+	if rows := privateForksInventory("octodemo", repositories, nil).Rows; rows[1][2] != "octodemo/api" || rows[1][4] != "" {
+		t.Errorf("unexpected private forks inventory %v", rows)
+	}
+}
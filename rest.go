@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// restPageSize is how many items are requested per page from REST list endpoints.
+const restPageSize = 100
+
+var nextPagePattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// getAllPages requests every page of a REST list endpoint, following the Link header, and passes
+// the body of each page to decode.
+func getAllPages(client api.RESTClient, path string, decode func(body []byte) error) error {
+	for path != "" {
+		response, err := client.Request(http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+
+		body, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := decode(body); err != nil {
+			return err
+		}
+
+		path = nextPage(response.Header.Get("Link"))
+	}
+
+	return nil
+}
+
+// nextPage returns the URL of the next page in a Link header, or "" on the last page.
+func nextPage(link string) string {
+	match := nextPagePattern.FindStringSubmatch(link)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+// isForbidden tells whether a REST request was refused, for example because the token is missing a scope.
+func isForbidden(err error) bool {
+	var httpErr api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden
+}
+
+// isPermissionError tells whether a request failed because the token may not read the resource. GitHub
+// answers 404 rather than 403 for resources it hides from the token, and GraphQL reports either as an
+// error type.
+func isPermissionError(err error) bool {
+	var gqlErr api.GQLError
+	if errors.As(err, &gqlErr) {
+		for _, item := range gqlErr.Errors {
+			if item.Type == "FORBIDDEN" || item.Type == "NOT_FOUND" {
+				return true
+			}
+		}
+	}
+
+	return isForbidden(err) || isNotFound(err)
+}
+
+// isNotFound tells whether a REST request failed because the resource does not exist, for example on
+// GitHub versions without the feature.
+func isNotFound(err error) bool {
+	var httpErr api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	statusPass    = "✓"
+	statusFail    = "✗"
+	statusUnknown = "?"
+)
+
+// Finding is the result of evaluating a single Rule against an organization and an enterprise.
+// SourceValue is the organization's current value, TargetValue the enterprise value the verdict
+// was based on, and EffectiveValue the value the organization will have after the transfer.
+type Finding struct {
+	RuleID         string
+	Policy         string
+	Category       string
+	Severity       Severity
+	SourceValue    string
+	TargetValue    string
+	EffectiveValue string
+	Comment        string
+	Remediation    string
+	Status         string
+}
+
+// Rule is a single policy check. Inputs lists the policy fields the rule reads so they can be
+// documented and reported alongside the finding. Severity is applied to failed findings that do
+// not set their own.
+type Rule struct {
+	ID       string
+	Policy   string
+	Category string
+	Severity Severity
+	Inputs   []string
+	Scopes   []string
+	Evaluate func(org *OrganizationPolicies, ent *EnterprisePolicies) Finding
+}
+
+var ruleRegistry []Rule
+
+// registerRule adds a built-in rule to the registry. Rule IDs must be unique.
+func registerRule(rule Rule) {
+	if err := addRule(rule); err != nil {
+		panic(err)
+	}
+}
+
+// addRule adds a rule to the registry, returning an error if its ID is already registered.
+func addRule(rule Rule) error {
+	for _, r := range ruleRegistry {
+		if r.ID == rule.ID {
+			return fmt.Errorf("rule %q registered twice", rule.ID)
+		}
+	}
+
+	ruleRegistry = append(ruleRegistry, rule)
+
+	return nil
+}
+
+// runRules evaluates every registered rule in registration order and returns one finding per rule.
+func runRules(org *OrganizationPolicies, ent *EnterprisePolicies) []Finding {
+	findings := make([]Finding, 0, len(ruleRegistry))
+
+	for _, rule := range ruleRegistry {
+		findings = append(findings, evaluateRule(rule, org, ent))
+	}
+
+	return findings
+}
+
+// evaluateRule evaluates a single rule and fills in the finding's rule metadata.
+func evaluateRule(rule Rule, org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	return withRuleMetadata(rule, rule.Evaluate(org, ent))
+}
+
+// withRuleMetadata fills in the rule metadata of a finding. Checks over data collected outside
+// OrganizationPolicies and EnterprisePolicies describe themselves with a Rule without Evaluate.
+func withRuleMetadata(rule Rule, finding Finding) Finding {
+	finding.RuleID = rule.ID
+	finding.Policy = rule.Policy
+	finding.Category = rule.Category
+	if finding.Severity == 0 {
+		finding.Severity = SeverityInfo
+		if finding.Status != statusPass {
+			finding.Severity = rule.Severity
+		}
+	}
+
+	return finding
+}
+
+// uncollectedFinding reports a rule as unknown because the data it checks could not be collected.
+// When the token was refused, the remediation names the scopes the rule needs.
+func uncollectedFinding(rule Rule, err error) Finding {
+	finding := Finding{
+		Comment:     fmt.Sprintf("Could not collect the %s: %s.", strings.ToLower(rule.Policy), err),
+		Remediation: "Check the error and run the audit again.",
+		Status:      statusUnknown,
+	}
+
+	if isPermissionError(err) {
+		finding.Comment = fmt.Sprintf("The token is not allowed to read the %s: %s.", strings.ToLower(rule.Policy), err)
+		finding.Remediation = "Run the audit with a token that has owner access to the Organization and the Enterprise."
+		if len(rule.Scopes) > 0 {
+			finding.Remediation = fmt.Sprintf("Run the audit as an owner of the Organization and the Enterprise with a token that has the %s scopes.", strings.Join(rule.Scopes, ", "))
+		}
+	}
+
+	return withRuleMetadata(rule, finding)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cli/go-gh/pkg/api"
+)
+
+func TestComparePoliciesRunsEveryRule(t *testing.T) {
+	org := new(OrganizationPolicies)
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id = "ent-idp"
+	org.GQL.Organization.SamlIdentityProvider.Id = "org-idp"
+
+	findings := comparePolicies(org, ent)
+
+	if len(findings) != len(ruleRegistry) {
+		t.Fatalf("expected %d findings, got %d", len(ruleRegistry), len(findings))
+	}
+
+	byID := make(map[string]Finding)
+	for _, finding := range findings {
+		byID[finding.RuleID] = finding
+	}
+
+	if got := byID["two-factor-authentication"].Status; got != statusFail {
+		t.Errorf("two-factor-authentication status = %q, want %q", got, statusFail)
+	}
+
+	saml := byID["saml-identity-provider"]
+	if saml.Status != statusFail {
+		t.Errorf("saml-identity-provider status = %q, want %q", saml.Status, statusFail)
+	}
+	if saml.Category != "account" || saml.Policy != "SAML Identity Provider" {
+		t.Errorf("saml-identity-provider metadata not copied from rule: %+v", saml)
+	}
+}
+
+func TestRegisterRuleRejectsDuplicateIDs(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a duplicate rule ID to panic")
+		}
+	}()
+
+	registerRule(Rule{ID: "two-factor-authentication"})
+}
+
+func TestEveryEnterpriseSettingIsCompared(t *testing.T) {
+	inputs := make(map[string]bool)
+	for _, rule := range ruleRegistry {
+		for _, input := range rule.Inputs {
+			inputs[input] = true
+		}
+	}
+
+	ownerInfo := reflect.TypeOf(EnterprisePolicies{}.Enterprise.OwnerInfo)
+	for i := 0; i < ownerInfo.NumField(); i++ {
+		name := "enterprise." + ownerInfo.Field(i).Name
+		if !inputs[name] {
+			t.Errorf("no rule compares %s", name)
+		}
+	}
+}
+
+func TestCompareRESTPolicies(t *testing.T) {
+	org := new(OrganizationPolicies)
+	enabled := true
+	org.REST.Default_repository_permission = "write"
+	org.REST.Members_can_create_public_repositories = &enabled
+	org.REST.Members_can_create_private_repositories = &enabled
+	org.REST.Has_repository_projects = true
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.DefaultRepositoryPermissionSetting = "READ"
+	ent.Enterprise.OwnerInfo.MembersCanCreateRepositoriesSetting = "PRIVATE"
+	ent.Enterprise.OwnerInfo.RepositoryProjectsSetting = "ENABLED"
+
+	byID := make(map[string]Finding)
+	for _, finding := range comparePolicies(org, ent) {
+		byID[finding.RuleID] = finding
+	}
+
+	tests := []struct {
+		id     string
+		status string
+	}{
+		{"default-repository-permission", statusFail},
+		{"members-can-create-repositories", statusFail},
+		{"repository-projects", statusPass},
+		{"organization-projects", statusPass},
+	}
+
+	for _, tt := range tests {
+		if got := byID[tt.id].Status; got != tt.status {
+			t.Errorf("%s status = %q, want %q (%s)", tt.id, got, tt.status, byID[tt.id].Comment)
+		}
+	}
+
+	if got := byID["members-can-create-repositories"].Comment; got != "Members will no longer be able to create public repositories." {
+		t.Errorf("unexpected members-can-create-repositories comment %q", got)
+	}
+
+	if finding := compareDefaultRepositoryPermission(new(OrganizationPolicies), ent); finding.Status != statusUnknown {
+		t.Errorf("expected an unreported base repository permission to be unknown, got %+v", finding)
+	}
+}
+
+func TestTransferReadiness(t *testing.T) {
+	findings := []Finding{
+		{Status: statusPass, Severity: SeverityInfo},
+		{Status: statusFail, Severity: SeverityBlocker},
+		{Status: statusFail, Severity: SeverityMedium},
+		{Status: statusUnknown, Severity: SeverityHigh},
+	}
+
+	readiness := transferReadiness("octodemo", findings)
+
+	if readiness.Score != 70 {
+		t.Errorf("score = %d, want 70", readiness.Score)
+	}
+	if readiness.Failed != 2 || readiness.Blockers != 1 || readiness.Unknown != 1 {
+		t.Errorf("unexpected counts %+v", readiness)
+	}
+	if readiness.Ready() {
+		t.Error("expected an organization with a blocker not to be ready")
+	}
+
+	if err := checkReadinessGate(readiness, findings, 50, "high"); err == nil {
+		t.Error("expected -fail-on high to fail on a blocker finding")
+	}
+	if err := checkReadinessGate(readiness, findings, 80, ""); err == nil {
+		t.Error("expected -min-score 80 to fail on a score of 70")
+	}
+	if err := checkReadinessGate(readiness, findings, 70, ""); err != nil {
+		t.Errorf("unexpected gate error: %s", err)
+	}
+}
+
+func TestFailedFindingsTakeRuleSeverity(t *testing.T) {
+	org := new(OrganizationPolicies)
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "ENABLED"
+
+	for _, finding := range comparePolicies(org, ent) {
+		if finding.RuleID == "two-factor-authentication" && finding.Severity != SeverityBlocker {
+			t.Errorf("two-factor-authentication severity = %s, want blocker", finding.Severity)
+		}
+		if finding.Status == statusPass && finding.Severity != SeverityInfo {
+			t.Errorf("%s passed with severity %s", finding.RuleID, finding.Severity)
+		}
+	}
+}
+
+func TestCompareUnrecognizedEnterpriseSettings(t *testing.T) {
+	org := new(OrganizationPolicies)
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = "DISABLED"
+	ent.Enterprise.OwnerInfo.AllowPrivateRepositoryForkingSetting = "UNKNOWN"
+
+	for _, finding := range []Finding{compareTwoFactorAuthentication(org, ent), comparePrivateRepositoryForking(org, ent)} {
+		if finding.Status != statusUnknown || finding.Comment == "" {
+			t.Errorf("expected an unknown finding with a comment, got %+v", finding)
+		}
+	}
+
+	ent.Enterprise.OwnerInfo.TwoFactorRequiredSetting = ""
+	if finding := compareTwoFactorAuthentication(org, ent); finding.Status != statusPass {
+		t.Errorf("expected an empty setting to count as no policy, got %+v", finding)
+	}
+}
+
+func TestUncollectedFinding(t *testing.T) {
+	rule := Rule{ID: "webhook-deliveries", Policy: "Webhook Deliveries", Severity: SeverityMedium, Scopes: []string{"admin:org_hook", "admin:repo_hook"}}
+
+	refused := fmt.Errorf("listing hooks: %w", api.HTTPError{StatusCode: 403, Message: "Resource not accessible by integration"})
+	finding := uncollectedFinding(rule, refused)
+	if finding.Status != statusUnknown || finding.Remediation != "Run the audit as an owner of the Organization and the Enterprise with a token that has the admin:org_hook, admin:repo_hook scopes." {
+		t.Errorf("expected a refused request to name the scopes, got %+v", finding)
+	}
+
+	hidden := api.GQLError{Errors: []api.GQLErrorItem{{Type: "NOT_FOUND", Message: "Could not resolve to an Enterprise"}}}
+	if finding := uncollectedFinding(rule, hidden); finding.Remediation != "Run the audit as an owner of the Organization and the Enterprise with a token that has the admin:org_hook, admin:repo_hook scopes." {
+		t.Errorf("expected a hidden GraphQL resource to name the scopes, got %+v", finding)
+	}
+
+	finding = uncollectedFinding(rule, errors.New("connection reset by peer"))
+	if finding.Status != statusUnknown || finding.Remediation != "Check the error and run the audit again." {
+		t.Errorf("expected other errors to keep a generic remediation, got %+v", finding)
+	}
+}
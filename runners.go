@@ -0,0 +1,474 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+type RunnerLabel struct {
+	Name string
+	Type string
+}
+
+type Runner struct {
+	Id     int64
+	Name   string
+	Os     string
+	Status string
+	Busy   bool
+	Labels []RunnerLabel
+}
+
+// customLabels returns the labels added to a runner, leaving out the read-only ones such as
+// self-hosted, linux and x64 that every runner of a platform has.
+func (r Runner) customLabels() []string {
+	var labels []string
+	for _, label := range r.Labels {
+		if label.Type == "custom" {
+			labels = append(labels, label.Name)
+		}
+	}
+
+	return labels
+}
+
+// RunnerGroup is a runner group of an organization or an enterprise. Organization groups are shared
+// with the Repositories they select and enterprise groups with the Organizations they select, unless
+// their Visibility is "all".
+type RunnerGroup struct {
+	Id                         int64
+	Name                       string
+	Visibility                 string
+	Default                    bool
+	Inherited                  bool
+	Allows_public_repositories bool
+	Restricted_to_workflows    bool
+	Selected_workflows         []string
+	Repositories               []string `json:"-"`
+	Organizations              []string `json:"-"`
+	Runners                    []Runner `json:"-"`
+}
+
+func getOrganizationRunnerGroups(org string) ([]RunnerGroup, error) {
+	return getRunnerGroups(fmt.Sprintf("orgs/%s", org))
+}
+
+func getEnterpriseRunnerGroups(ent string) ([]RunnerGroup, error) {
+	return getRunnerGroups(fmt.Sprintf("enterprises/%s", ent))
+}
+
+// getRunnerGroups collects the runner groups under an orgs/ or enterprises/ REST path, with their
+// runners and the repositories or organizations they are shared with.
+func getRunnerGroups(owner string) ([]RunnerGroup, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []RunnerGroup
+
+	err = getAllPages(client, fmt.Sprintf("%s/actions/runner-groups?per_page=%d", owner, restPageSize), func(body []byte) error {
+		var page struct {
+			Runner_groups []RunnerGroup
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		groups = append(groups, page.Runner_groups...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		group := &groups[i]
+		groupPath := fmt.Sprintf("%s/actions/runner-groups/%d", owner, group.Id)
+
+		if group.Runners, err = getRunnerGroupRunners(client, groupPath); err != nil {
+			return nil, err
+		}
+
+		// inherited groups belong to the enterprise, the organization cannot list who they are shared with
+		if group.Visibility != "selected" || group.Inherited {
+			continue
+		}
+
+		if strings.HasPrefix(owner, "enterprises/") {
+			group.Organizations, err = getRunnerGroupOrganizations(client, groupPath)
+		} else {
+			group.Repositories, err = getRunnerGroupRepositories(client, groupPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+func getRunnerGroupRunners(client api.RESTClient, groupPath string) ([]Runner, error) {
+	var runners []Runner
+
+	err := getAllPages(client, fmt.Sprintf("%s/runners?per_page=%d", groupPath, restPageSize), func(body []byte) error {
+		var page struct {
+			Runners []Runner
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		runners = append(runners, page.Runners...)
+
+		return nil
+	})
+
+	return runners, err
+}
+
+func getRunnerGroupRepositories(client api.RESTClient, groupPath string) ([]string, error) {
+	var repositories []string
+
+	err := getAllPages(client, fmt.Sprintf("%s/repositories?per_page=%d", groupPath, restPageSize), func(body []byte) error {
+		var page struct {
+			Repositories []struct {
+				Full_name string
+			}
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, repository := range page.Repositories {
+			repositories = append(repositories, repository.Full_name)
+		}
+
+		return nil
+	})
+
+	return repositories, err
+}
+
+func getRunnerGroupOrganizations(client api.RESTClient, groupPath string) ([]string, error) {
+	var organizations []string
+
+	err := getAllPages(client, fmt.Sprintf("%s/organizations?per_page=%d", groupPath, restPageSize), func(body []byte) error {
+		var page struct {
+			Organizations []struct {
+				Login string
+			}
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		for _, organization := range page.Organizations {
+			organizations = append(organizations, organization.Login)
+		}
+
+		return nil
+	})
+
+	return organizations, err
+}
+
+// sharedRunnerGroups returns the enterprise runner groups that will be shared with a newly joined
+// organization. Groups shared with selected organizations do not include it yet.
+func sharedRunnerGroups(entGroups []RunnerGroup) []RunnerGroup {
+	var shared []RunnerGroup
+	for _, group := range entGroups {
+		if group.Visibility == "all" {
+			shared = append(shared, group)
+		}
+	}
+
+	return shared
+}
+
+// runnerGroupsInventory lists the runner groups of an organization or an enterprise and who may use them.
+func runnerGroupsInventory(owner string, groups []RunnerGroup) Inventory {
+	inventory := Inventory{
+		Name:    "Runner groups: " + owner,
+		Columns: []string{"Group", "Visibility", "Default", "Inherited", "Public Repositories", "Shared With", "Workflows", "Runners"},
+	}
+
+	for _, group := range groups {
+		workflows := "all"
+		if group.Restricted_to_workflows {
+			workflows = strings.Join(group.Selected_workflows, ", ")
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			group.Name,
+			group.Visibility,
+			strconv.FormatBool(group.Default),
+			strconv.FormatBool(group.Inherited),
+			strconv.FormatBool(group.Allows_public_repositories),
+			strings.Join(group.Repositories, ", ") + strings.Join(group.Organizations, ", "),
+			workflows,
+			strconv.Itoa(len(group.Runners)),
+		})
+	}
+
+	return inventory
+}
+
+// runnersInventory lists the self-hosted runners of every runner group.
+func runnersInventory(owner string, groups []RunnerGroup) Inventory {
+	inventory := Inventory{
+		Name:    "Self-hosted runners: " + owner,
+		Columns: []string{"Runner", "Group", "OS", "Status", "Busy", "Labels"},
+	}
+
+	for _, group := range groups {
+		for _, runner := range group.Runners {
+			labels := make([]string, 0, len(runner.Labels))
+			for _, label := range runner.Labels {
+				labels = append(labels, label.Name)
+			}
+
+			inventory.Rows = append(inventory.Rows, []string{
+				runner.Name,
+				group.Name,
+				runner.Os,
+				runner.Status,
+				strconv.FormatBool(runner.Busy),
+				strings.Join(labels, ", "),
+			})
+		}
+	}
+
+	return inventory
+}
+
+// runnerCheck compares the runners of an organization with the enterprise runner groups it will inherit.
+type runnerCheck struct {
+	rule    Rule
+	compare func(orgGroups []RunnerGroup, entGroups []RunnerGroup) Finding
+}
+
+// runnerScopes are the token scopes needed to collect the data of every runner check.
+var runnerScopes = []string{"admin:org", "manage_runners:enterprise"}
+
+var runnerChecks = []runnerCheck{
+	{Rule{ID: "runner-groups-shared", Policy: "Shared Enterprise Runner Groups", Category: "actions", Severity: SeverityLow,
+		Inputs: []string{"enterprise.runnerGroups.Visibility", "enterprise.runnerGroups.Restricted_to_workflows"}, Scopes: runnerScopes}, compareSharedRunnerGroups},
+	{Rule{ID: "runner-group-names", Policy: "Runner Group Names", Category: "actions", Severity: SeverityMedium,
+		Inputs: []string{"enterprise.runnerGroups.Name", "organization.runnerGroups.Name"}, Scopes: runnerScopes}, compareRunnerGroupNames},
+	{Rule{ID: "runner-labels", Policy: "Self-Hosted Runner Labels", Category: "actions", Severity: SeverityMedium,
+		Inputs: []string{"enterprise.runners.Labels", "organization.runners.Labels"}, Scopes: runnerScopes}, compareRunnerLabels},
+	{Rule{ID: "runner-public-repositories", Policy: "Self-Hosted Runners For Public Repositories", Category: "actions", Severity: SeverityHigh,
+		Inputs: []string{"organization.runnerGroups.Allows_public_repositories"}, Scopes: runnerScopes}, compareRunnerPublicRepositories},
+}
+
+// auditRunners collects the runner groups of an organization and an enterprise and compares them.
+// When they cannot be collected every runner check is reported as unknown.
+func auditRunners(org string, ent string) ([]Finding, []Inventory) {
+	orgGroups, err := getOrganizationRunnerGroups(org)
+	if err != nil {
+		return uncollectedRunnerFindings(err), nil
+	}
+
+	entGroups, err := getEnterpriseRunnerGroups(ent)
+	if err != nil {
+		return uncollectedRunnerFindings(err), nil
+	}
+
+	return compareRunners(orgGroups, entGroups), []Inventory{
+		runnerGroupsInventory(org, orgGroups),
+		runnersInventory(org, orgGroups),
+		runnerGroupsInventory(ent, entGroups),
+		runnersInventory(ent, entGroups),
+	}
+}
+
+// compareRunners returns one finding per runner check.
+func compareRunners(orgGroups []RunnerGroup, entGroups []RunnerGroup) []Finding {
+	fmt.Println("Comparing Organization and Enterprise Runner Groups")
+
+	findings := make([]Finding, 0, len(runnerChecks))
+	for _, check := range runnerChecks {
+		findings = append(findings, withRuleMetadata(check.rule, check.compare(orgGroups, entGroups)))
+	}
+
+	return findings
+}
+
+// uncollectedRunnerFindings reports every runner check as unknown when the runner groups could not be collected.
+func uncollectedRunnerFindings(err error) []Finding {
+	findings := make([]Finding, 0, len(runnerChecks))
+	for _, check := range runnerChecks {
+		findings = append(findings, uncollectedFinding(check.rule, err))
+	}
+
+	return findings
+}
+
+func runnerGroupNames(groups []RunnerGroup) []string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+
+	return names
+}
+
+func compareSharedRunnerGroups(orgGroups []RunnerGroup, entGroups []RunnerGroup) Finding {
+	shared := sharedRunnerGroups(entGroups)
+	finding := Finding{
+		SourceValue:    strings.Join(runnerGroupNames(orgGroups), ", "),
+		TargetValue:    strings.Join(runnerGroupNames(entGroups), ", "),
+		EffectiveValue: strings.Join(append(runnerGroupNames(orgGroups), runnerGroupNames(shared)...), ", "),
+	}
+
+	if len(shared) == 0 {
+		finding.Comment = "No Enterprise runner group is shared with every Organization. The Organization keeps its own runner groups."
+		finding.Status = statusPass
+		return finding
+	}
+
+	var restricted []string
+	for _, group := range shared {
+		if group.Restricted_to_workflows {
+			restricted = append(restricted, group.Name)
+		}
+	}
+
+	finding.Comment = fmt.Sprintf("The Organization will inherit the Enterprise runner groups %s next to its own.", strings.Join(runnerGroupNames(shared), ", "))
+	if len(restricted) > 0 {
+		finding.Comment += fmt.Sprintf(" %s only run selected workflows.", strings.Join(restricted, ", "))
+	}
+	finding.Status = statusPass
+
+	return finding
+}
+
+func compareRunnerGroupNames(orgGroups []RunnerGroup, entGroups []RunnerGroup) Finding {
+	shared := sharedRunnerGroups(entGroups)
+	finding := Finding{
+		SourceValue: strings.Join(runnerGroupNames(orgGroups), ", "),
+		TargetValue: strings.Join(runnerGroupNames(shared), ", "),
+	}
+	finding.EffectiveValue = finding.SourceValue
+
+	names := make(map[string]bool)
+	for _, group := range orgGroups {
+		if !group.Inherited {
+			names[strings.ToLower(group.Name)] = true
+		}
+	}
+
+	var conflicts []string
+	for _, group := range shared {
+		if names[strings.ToLower(group.Name)] {
+			conflicts = append(conflicts, group.Name)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		finding.Comment = "No inherited Enterprise runner group has the name of an Organization runner group."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The inherited Enterprise runner groups %s have the same names as Organization runner groups. Workflows that select a runner group by name may run on the wrong runners.", strings.Join(conflicts, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Rename the Organization runner groups, or update the workflows that select them, before the transfer."
+
+	return finding
+}
+
+func compareRunnerLabels(orgGroups []RunnerGroup, entGroups []RunnerGroup) Finding {
+	orgLabels := make(map[string]bool)
+	for _, group := range orgGroups {
+		if group.Inherited {
+			continue
+		}
+		for _, runner := range group.Runners {
+			for _, label := range runner.customLabels() {
+				orgLabels[strings.ToLower(label)] = true
+			}
+		}
+	}
+
+	entLabels := make(map[string]bool)
+	for _, group := range sharedRunnerGroups(entGroups) {
+		for _, runner := range group.Runners {
+			for _, label := range runner.customLabels() {
+				entLabels[strings.ToLower(label)] = true
+			}
+		}
+	}
+
+	var conflicts []string
+	for label := range orgLabels {
+		if entLabels[label] {
+			conflicts = append(conflicts, label)
+		}
+	}
+	sort.Strings(conflicts)
+
+	finding := Finding{
+		SourceValue:    strings.Join(sortedKeys(orgLabels), ", "),
+		TargetValue:    strings.Join(sortedKeys(entLabels), ", "),
+		EffectiveValue: strings.Join(conflicts, ", "),
+	}
+
+	if len(conflicts) == 0 {
+		finding.Comment = "The runners of the inherited Enterprise runner groups do not share custom labels with the Organization's runners."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("Runners of the Organization and of inherited Enterprise runner groups share the labels %s. Jobs that run on these labels may be picked up by Enterprise runners.", strings.Join(conflicts, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Give the Organization's runners distinct labels, or select them by runner group in workflows, before the transfer."
+
+	return finding
+}
+
+// compareRunnerPublicRepositories cannot read the Enterprise policy on self-hosted runners for public
+// repositories, and Enterprise runner groups only describe themselves, so public use is left to confirm.
+func compareRunnerPublicRepositories(orgGroups []RunnerGroup, entGroups []RunnerGroup) Finding {
+	var public []string
+	for _, group := range orgGroups {
+		if group.Allows_public_repositories && !group.Inherited && len(group.Runners) > 0 {
+			public = append(public, group.Name)
+		}
+	}
+
+	finding := Finding{
+		SourceValue:    strings.Join(public, ", "),
+		EffectiveValue: strings.Join(public, ", "),
+	}
+
+	if len(public) == 0 {
+		finding.Comment = "No Organization runner group runs jobs for public repositories."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The Organization runner groups %s run jobs for public repositories. Whether the Enterprise allows self-hosted runners for public repositories could not be collected.", strings.Join(public, ", "))
+	finding.Status = statusUnknown
+	finding.Remediation = "Confirm with the Enterprise owners that self-hosted runners may run jobs for public repositories, or move those jobs to GitHub-hosted runners."
+
+	return finding
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func testRunner(name string, labels ...string) Runner {
+	runner := Runner{Name: name, Os: "linux", Status: "online", Labels: []RunnerLabel{{Name: "self-hosted", Type: "read-only"}}}
+	for _, label := range labels {
+		runner.Labels = append(runner.Labels, RunnerLabel{Name: label, Type: "custom"})
+	}
+
+	return runner
+}
+
+func TestCompareRunners(t *testing.T) {
+	orgGroups := []RunnerGroup{
+		{Name: "Default", Visibility: "all", Default: true, Runners: []Runner{testRunner("build-1", "gpu")}},
+		{Name: "Deploy", Visibility: "selected", Allows_public_repositories: true, Repositories: []string{"octodemo/site"}, Runners: []Runner{testRunner("deploy-1", "deploy")}},
+	}
+	entGroups := []RunnerGroup{
+		{Name: "deploy", Visibility: "all", Runners: []Runner{testRunner("ent-deploy-1", "GPU")}},
+		{Name: "Restricted", Visibility: "selected", Organizations: []string{"octo-team"}, Runners: []Runner{testRunner("ent-1", "deploy")}},
+	}
+
+	findings := make(map[string]Finding)
+	for _, finding := range compareRunners(orgGroups, entGroups) {
+		findings[finding.RuleID] = finding
+	}
+
+	tests := map[string]string{
+		"runner-groups-shared":       statusPass,
+		"runner-group-names":         statusFail,
+		"runner-labels":              statusFail,
+		"runner-public-repositories": statusUnknown,
+	}
+
+	for id, want := range tests {
+		if got := findings[id]; got.Status != want {
+			t.Errorf("%s: expected %s, got %+v", id, want, got)
+		}
+	}
+
+	if labels := findings["runner-labels"].EffectiveValue; labels != "gpu" {
+		t.Errorf("expected only the labels of shared Enterprise runners to conflict, got %q", labels)
+	}
+}
+
+func TestRunnerInventories(t *testing.T) {
+	groups := []RunnerGroup{
+		{Name: "Deploy", Visibility: "selected", Repositories: []string{"octodemo/site", "octodemo/api"}, Restricted_to_workflows: true,
+			Selected_workflows: []string{"octodemo/site/.github/workflows/deploy.yml@main"}, Runners: []Runner{testRunner("deploy-1", "deploy")}},
+	}
+
+	groupRows := runnerGroupsInventory("octodemo", groups).Rows
+	if groupRows[0][5] != "octodemo/site, octodemo/api" || groupRows[0][6] != "octodemo/site/.github/workflows/deploy.yml@main" || groupRows[0][7] != "1" {
+		t.Errorf("unexpected runner group row %v", groupRows[0])
+	}
+
+	runnerRows := runnersInventory("octodemo", groups).Rows
+	if runnerRows[0][1] != "Deploy" || runnerRows[0][5] != "self-hosted, deploy" {
+		t.Errorf("unexpected runner row %v", runnerRows[0])
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	link := `<https://api.github.com/orgs/octodemo/actions/runner-groups?per_page=100&page=2>; rel="next", <https://api.github.com/orgs/octodemo/actions/runner-groups?per_page=100&page=3>; rel="last"`
+
+	if got := nextPage(link); got != "https://api.github.com/orgs/octodemo/actions/runner-groups?per_page=100&page=2" {
+		t.Errorf("unexpected next page %q", got)
+	}
+	if got := nextPage(`<https://api.github.com/orgs/octodemo/actions/runner-groups?page=1>; rel="prev"`); got != "" {
+		t.Errorf("expected no next page on the last page, got %q", got)
+	}
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh"
+	graphql "github.com/cli/shurcooL-graphql"
+)
+
+// ExternalIdentity is an identity linked through a SAML identity provider, and through SCIM when the
+// provider provisions users. User is empty when the identity is not linked to a GitHub account.
+type ExternalIdentity struct {
+	Guid         string
+	SamlIdentity struct {
+		NameId string
+	}
+	ScimIdentity struct {
+		Username string
+	}
+	User struct {
+		Login string
+	}
+}
+
+type ExternalIdentities struct {
+	Nodes    []ExternalIdentity
+	PageInfo PageInfo
+}
+
+type organizationExternalIdentitiesPage struct {
+	Organization struct {
+		SamlIdentityProvider struct {
+			ExternalIdentities ExternalIdentities `graphql:"externalIdentities(first: $first, after: $after)"`
+		}
+	} `graphql:"organization(login: $login)"`
+}
+
+type enterpriseExternalIdentitiesPage struct {
+	Enterprise struct {
+		OwnerInfo struct {
+			SamlIdentityProvider struct {
+				ExternalIdentities ExternalIdentities `graphql:"externalIdentities(first: $first, after: $after)"`
+			}
+		}
+	} `graphql:"enterprise(slug: $slug)"`
+}
+
+// externalIdentitiesPageSize is how many external identities are requested per page.
+const externalIdentitiesPageSize = 100
+
+// getOrganizationExternalIdentities fetches every identity linked through the SAML identity provider of an organization.
+func getOrganizationExternalIdentities(org string) ([]ExternalIdentity, error) {
+	client, err := gh.GQLClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []ExternalIdentity
+	var after *graphql.String
+
+	for {
+		page := new(organizationExternalIdentitiesPage)
+
+		variables := map[string]interface{}{
+			"login": graphql.String(org),
+			"first": graphql.Int(externalIdentitiesPageSize),
+			"after": after,
+		}
+
+		if err := client.Query("OrganizationExternalIdentities", page, variables); err != nil {
+			return nil, err
+		}
+
+		connection := page.Organization.SamlIdentityProvider.ExternalIdentities
+		identities = append(identities, connection.Nodes...)
+
+		if !connection.PageInfo.HasNextPage {
+			return identities, nil
+		}
+
+		after = graphql.NewString(graphql.String(connection.PageInfo.EndCursor))
+	}
+}
+
+// getEnterpriseExternalIdentities fetches every identity linked through the SAML identity provider of an enterprise.
+func getEnterpriseExternalIdentities(ent string) ([]ExternalIdentity, error) {
+	client, err := gh.GQLClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []ExternalIdentity
+	var after *graphql.String
+
+	for {
+		page := new(enterpriseExternalIdentitiesPage)
+
+		variables := map[string]interface{}{
+			"slug":  graphql.String(ent),
+			"first": graphql.Int(externalIdentitiesPageSize),
+			"after": after,
+		}
+
+		if err := client.Query("EnterpriseExternalIdentities", page, variables); err != nil {
+			return nil, err
+		}
+
+		connection := page.Enterprise.OwnerInfo.SamlIdentityProvider.ExternalIdentities
+		identities = append(identities, connection.Nodes...)
+
+		if !connection.PageInfo.HasNextPage {
+			return identities, nil
+		}
+
+		after = graphql.NewString(graphql.String(connection.PageInfo.EndCursor))
+	}
+}
+
+// getOrganizationMembers lists the owners and members of an organization.
+func getOrganizationMembers(org string) ([]OrganizationUser, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []OrganizationUser
+
+	for role, apiRole := range map[string]string{roleOwner: "admin", roleMember: "member"} {
+		logins, err := getLogins(client, fmt.Sprintf("orgs/%s/members?role=%s&per_page=%d", org, apiRole, restPageSize))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, login := range logins {
+			users = append(users, OrganizationUser{Login: login, Role: role})
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Login < users[j].Login
+	})
+
+	return users, nil
+}
+
+// Day one access of a member once the enterprise identity provider applies.
+const (
+	samlAccessLinked   = "linked with the Enterprise identity provider"
+	samlAccessUnlinked = "loses access until linked with the Enterprise identity provider"
+	samlAccessNoSaml   = "no change"
+)
+
+// SamlIdentityCoverage is how the members of an organization are linked with the organization and
+// enterprise identity providers.
+type SamlIdentityCoverage struct {
+	Members []SamlMemberCoverage
+	// Unmatched are organization identities that are not linked to a member of the organization.
+	Unmatched []ExternalIdentity
+}
+
+type SamlMemberCoverage struct {
+	Member     OrganizationUser
+	OrgNameId  string
+	ScimUser   string
+	EntNameId  string
+	DayOneLoss bool
+}
+
+// analyzeSamlIdentityCoverage matches the members of an organization with the identities linked
+// through the organization and enterprise identity providers. Members without an enterprise identity
+// are estimated to lose access on day one when the enterprise has an identity provider.
+func analyzeSamlIdentityCoverage(members []OrganizationUser, orgIdentities []ExternalIdentity, entIdentities []ExternalIdentity, entSaml bool) SamlIdentityCoverage {
+	orgByLogin := make(map[string]ExternalIdentity)
+	for _, identity := range orgIdentities {
+		if identity.User.Login != "" {
+			orgByLogin[identity.User.Login] = identity
+		}
+	}
+
+	entByLogin := make(map[string]ExternalIdentity)
+	for _, identity := range entIdentities {
+		if identity.User.Login != "" {
+			entByLogin[identity.User.Login] = identity
+		}
+	}
+
+	var coverage SamlIdentityCoverage
+	isMember := make(map[string]bool)
+
+	for _, member := range members {
+		isMember[member.Login] = true
+
+		orgIdentity := orgByLogin[member.Login]
+		entIdentity, linked := entByLogin[member.Login]
+
+		coverage.Members = append(coverage.Members, SamlMemberCoverage{
+			Member:     member,
+			OrgNameId:  orgIdentity.SamlIdentity.NameId,
+			ScimUser:   orgIdentity.ScimIdentity.Username,
+			EntNameId:  entIdentity.SamlIdentity.NameId,
+			DayOneLoss: entSaml && !linked,
+		})
+	}
+
+	for _, identity := range orgIdentities {
+		if !isMember[identity.User.Login] {
+			coverage.Unmatched = append(coverage.Unmatched, identity)
+		}
+	}
+
+	return coverage
+}
+
+// samlIdentityName describes an identity by its NameID, or by its SCIM user name or GUID when it has none.
+func samlIdentityName(identity ExternalIdentity) string {
+	switch {
+	case identity.SamlIdentity.NameId != "":
+		return identity.SamlIdentity.NameId
+	case identity.ScimIdentity.Username != "":
+		return identity.ScimIdentity.Username
+	}
+
+	return identity.Guid
+}
+
+// samlCoverageInventory lists every member with their linked identities, followed by the identities
+// that match no member.
+func samlCoverageInventory(org string, coverage SamlIdentityCoverage, entSaml bool) Inventory {
+	inventory := Inventory{
+		Name:    "SAML identity coverage: " + org,
+		Columns: []string{"Login", "Role", "Organization NameID", "SCIM User", "Enterprise NameID", "Day One"},
+	}
+
+	for _, member := range coverage.Members {
+		dayOne := samlAccessNoSaml
+		if entSaml {
+			dayOne = samlAccessLinked
+			if member.DayOneLoss {
+				dayOne = samlAccessUnlinked
+			}
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			member.Member.Login,
+			member.Member.Role,
+			member.OrgNameId,
+			member.ScimUser,
+			member.EntNameId,
+			dayOne,
+		})
+	}
+
+	for _, identity := range coverage.Unmatched {
+		inventory.Rows = append(inventory.Rows, []string{
+			identity.User.Login,
+			"no member",
+			identity.SamlIdentity.NameId,
+			identity.ScimIdentity.Username,
+			"",
+			"",
+		})
+	}
+
+	return inventory
+}
+
+var samlLinkedIdentitiesRule = Rule{
+	ID:       "saml-linked-identities",
+	Policy:   "SAML Linked Identities",
+	Category: "account",
+	Severity: SeverityBlocker,
+	Inputs:   []string{"enterprise.SamlIdentityProvider.externalIdentities", "organization.SamlIdentityProvider.externalIdentities", "organization.members"},
+	Scopes:   []string{"admin:org", "admin:enterprise"},
+}
+
+var samlUnmatchedIdentitiesRule = Rule{
+	ID:       "saml-unmatched-identities",
+	Policy:   "SAML Identities Without A Member",
+	Category: "account",
+	Severity: SeverityLow,
+	Inputs:   []string{"organization.SamlIdentityProvider.externalIdentities", "organization.members"},
+	Scopes:   []string{"admin:org", "admin:enterprise"},
+}
+
+// auditSamlIdentities checks that every member of an organization can sign in through the enterprise
+// identity provider on the day of the transfer.
+func auditSamlIdentities(org string, ent string, entPolicies *EnterprisePolicies) ([]Finding, []Inventory) {
+	uncollected := func(err error) ([]Finding, []Inventory) {
+		return []Finding{uncollectedFinding(samlLinkedIdentitiesRule, err), uncollectedFinding(samlUnmatchedIdentitiesRule, err)}, nil
+	}
+
+	members, err := getOrganizationMembers(org)
+	if err != nil {
+		return uncollected(err)
+	}
+
+	orgIdentities, err := getOrganizationExternalIdentities(org)
+	if err != nil {
+		return uncollected(err)
+	}
+
+	entSaml := entPolicies.Enterprise.OwnerInfo.SamlIdentityProvider.Id != ""
+
+	var entIdentities []ExternalIdentity
+	if entSaml {
+		if entIdentities, err = getEnterpriseExternalIdentities(ent); err != nil {
+			return uncollected(err)
+		}
+	}
+
+	coverage := analyzeSamlIdentityCoverage(members, orgIdentities, entIdentities, entSaml)
+
+	return []Finding{
+		withRuleMetadata(samlLinkedIdentitiesRule, compareSamlLinkedIdentities(coverage, entSaml)),
+		withRuleMetadata(samlUnmatchedIdentitiesRule, compareSamlUnmatchedIdentities(coverage)),
+	}, []Inventory{samlCoverageInventory(org, coverage, entSaml)}
+}
+
+func compareSamlLinkedIdentities(coverage SamlIdentityCoverage, entSaml bool) Finding {
+	var unlinkedOrg, dayOneLoss []string
+	for _, member := range coverage.Members {
+		if member.OrgNameId == "" {
+			unlinkedOrg = append(unlinkedOrg, member.Member.Login)
+		}
+		if member.DayOneLoss {
+			dayOneLoss = append(dayOneLoss, member.Member.Login)
+		}
+	}
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d of %d members linked", len(coverage.Members)-len(unlinkedOrg), len(coverage.Members)),
+		TargetValue:    fmt.Sprintf("%d of %d members linked", len(coverage.Members)-len(dayOneLoss), len(coverage.Members)),
+		EffectiveValue: fmt.Sprintf("%d members lose access", len(dayOneLoss)),
+	}
+
+	if !entSaml {
+		finding.TargetValue = ""
+		finding.EffectiveValue = "no change"
+		finding.Comment = "The Enterprise has no SAML identity provider. Members keep signing in as they do today."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if len(dayOneLoss) == 0 {
+		finding.Comment = "Every member of the Organization already has an identity linked with the Enterprise identity provider."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The members %s have no identity linked with the Enterprise identity provider and are estimated to lose access on the day of the transfer.", strings.Join(dayOneLoss, ", "))
+	if len(unlinkedOrg) > 0 {
+		finding.Comment += fmt.Sprintf(" The members %s have no identity linked with the Organization identity provider either.", strings.Join(unlinkedOrg, ", "))
+	}
+	finding.Status = statusFail
+	finding.Remediation = "Assign these members to the GitHub application in the Enterprise identity provider and ask them to authenticate before the transfer."
+
+	return finding
+}
+
+func compareSamlUnmatchedIdentities(coverage SamlIdentityCoverage) Finding {
+	var unmatched []string
+	for _, identity := range coverage.Unmatched {
+		unmatched = append(unmatched, samlIdentityName(identity))
+	}
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d identities without a member", len(unmatched)),
+		EffectiveValue: fmt.Sprintf("%d identities without a member", len(unmatched)),
+	}
+
+	if len(unmatched) == 0 {
+		finding.Comment = "Every identity linked with the Organization identity provider belongs to a member."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The identities %s are linked with the Organization identity provider but belong to no member.", strings.Join(unmatched, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Revoke the stale identities, or unassign these users from the GitHub application in the identity provider."
+
+	return finding
+}
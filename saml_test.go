@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func testExternalIdentity(login string, nameId string) ExternalIdentity {
+	var identity ExternalIdentity
+	identity.User.Login = login
+	identity.SamlIdentity.NameId = nameId
+
+	return identity
+}
+
+func TestAnalyzeSamlIdentityCoverage(t *testing.T) {
+	members := []OrganizationUser{
+		{Login: "hubot", Role: roleMember},
+		{Login: "monalisa", Role: roleOwner},
+		{Login: "octocat", Role: roleMember},
+	}
+	orgIdentities := []ExternalIdentity{
+		testExternalIdentity("monalisa", "monalisa@octodemo.com"),
+		testExternalIdentity("octocat", "octocat@octodemo.com"),
+		testExternalIdentity("", "former@octodemo.com"),
+	}
+	entIdentities := []ExternalIdentity{
+		testExternalIdentity("monalisa", "mona@github.com"),
+	}
+
+	coverage := analyzeSamlIdentityCoverage(members, orgIdentities, entIdentities, true)
+
+	var dayOneLoss []string
+	for _, member := range coverage.Members {
+		if member.DayOneLoss {
+			dayOneLoss = append(dayOneLoss, member.Member.Login)
+		}
+	}
+	if len(dayOneLoss) != 2 || dayOneLoss[0] != "hubot" || dayOneLoss[1] != "octocat" {
+		t.Errorf("expected hubot and octocat to lose access, got %v", dayOneLoss)
+	}
+
+	finding := compareSamlLinkedIdentities(coverage, true)
+	want := "The members hubot, octocat have no identity linked with the Enterprise identity provider and are estimated to lose access on the day of the transfer. The members hubot have no identity linked with the Organization identity provider either."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	finding = compareSamlUnmatchedIdentities(coverage)
+	if finding.Status != statusFail || finding.Comment != "The identities former@octodemo.com are linked with the Organization identity provider but belong to no member." {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	rows := samlCoverageInventory("octodemo", coverage, true).Rows
+	if len(rows) != 4 || rows[1][4] != "mona@github.com" || rows[3][1] != "no member" {
+		t.Errorf("unexpected inventory %v", rows)
+	}
+}
+
+func TestCompareSamlLinkedIdentitiesWithoutEnterpriseSaml(t *testing.T) {
+	coverage := analyzeSamlIdentityCoverage([]OrganizationUser{{Login: "hubot", Role: roleMember}}, nil, nil, false)
+
+	if finding := compareSamlLinkedIdentities(coverage, false); finding.Status != statusPass {
+		t.Errorf("expected no impact without an Enterprise identity provider, got %+v", finding)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SamlIdentityProvider is the SAML configuration of an organization or an enterprise. Organizations
+// return the signature and digest methods as URIs, enterprises as enum values; samlAlgorithm
+// normalizes both. IdpCertificate is the PEM or base64 encoded signing certificate.
+type SamlIdentityProvider struct {
+	Id              string
+	Issuer          string
+	SsoUrl          string
+	SignatureMethod string
+	DigestMethod    string
+	IdpCertificate  string
+}
+
+// samlCertificateExpiryWarning is how long before it expires a signing certificate is flagged.
+const samlCertificateExpiryWarning = 30 * 24 * time.Hour
+
+// samlAlgorithm turns an XML signature URI such as http://www.w3.org/2001/04/xmldsig-more#rsa-sha256
+// into the enum value the enterprise API uses, RSA_SHA256.
+func samlAlgorithm(method string) string {
+	if i := strings.LastIndex(method, "#"); i >= 0 {
+		method = method[i+1:]
+	}
+
+	return strings.ToUpper(strings.ReplaceAll(method, "-", "_"))
+}
+
+// certificate parses the signing certificate. It returns nil without an error when there is none.
+func (provider SamlIdentityProvider) certificate() (*x509.Certificate, error) {
+	if provider.IdpCertificate == "" {
+		return nil, nil
+	}
+
+	var der []byte
+	if block, _ := pem.Decode([]byte(provider.IdpCertificate)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(provider.IdpCertificate), ""))
+		if err != nil {
+			return nil, fmt.Errorf("the signing certificate is neither PEM nor base64 encoded: %w", err)
+		}
+		der = decoded
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// samlCertificateFingerprint is the SHA-256 fingerprint of a certificate, as identity providers show it.
+func samlCertificateFingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// samlProviderSettings flattens a SAML configuration into named settings.
+func samlProviderSettings(provider SamlIdentityProvider) []PolicySetting {
+	settings := []PolicySetting{
+		{"Issuer", provider.Issuer},
+		{"SsoUrl", provider.SsoUrl},
+		{"SignatureMethod", samlAlgorithm(provider.SignatureMethod)},
+		{"DigestMethod", samlAlgorithm(provider.DigestMethod)},
+	}
+
+	certificate, err := provider.certificate()
+	switch {
+	case err != nil:
+		settings = append(settings, PolicySetting{"Certificate", err.Error()})
+	case certificate != nil:
+		settings = append(settings,
+			PolicySetting{"CertificateSubject", certificate.Subject.String()},
+			PolicySetting{"CertificateNotAfter", certificate.NotAfter.UTC().Format(time.RFC3339)},
+			PolicySetting{"CertificateFingerprint", samlCertificateFingerprint(certificate)},
+		)
+	}
+
+	return settings
+}
+
+// samlProviderDiffSettings flattens the parts of a SAML configuration that decide who can sign in, named
+// for diffSettings. A missing provider is reported as not configured rather than as a missing value.
+func samlProviderDiffSettings(provider SamlIdentityProvider) []PolicySetting {
+	if provider.Id == "" {
+		return []PolicySetting{
+			{"SamlIdentityProviderIssuer", "not configured"},
+			{"SamlIdentityProviderSsoUrl", "not configured"},
+			{"SamlIdentityProviderCertificate", "not configured"},
+		}
+	}
+
+	return []PolicySetting{
+		{"SamlIdentityProviderIssuer", provider.Issuer},
+		{"SamlIdentityProviderSsoUrl", provider.SsoUrl},
+		{"SamlIdentityProviderCertificate", samlCertificateValue(provider)},
+	}
+}
+
+// samlProviderInventory lists the SAML configuration of an organization or an enterprise.
+func samlProviderInventory(owner string, provider SamlIdentityProvider) Inventory {
+	return settingsInventory("SAML identity provider: "+owner, samlProviderSettings(provider))
+}
+
+func init() {
+	registerRule(Rule{
+		ID:       "saml-idp-configuration",
+		Policy:   "SAML Identity Provider Configuration",
+		Category: "account",
+		Severity: SeverityHigh,
+		Inputs: []string{
+			"enterprise.SamlIdentityProvider.issuer", "enterprise.SamlIdentityProvider.ssoUrl",
+			"enterprise.SamlIdentityProvider.signatureMethod", "enterprise.SamlIdentityProvider.digestMethod",
+			"enterprise.SamlIdentityProvider.idpCertificate",
+			"organization.SamlIdentityProvider.issuer", "organization.SamlIdentityProvider.ssoUrl",
+			"organization.SamlIdentityProvider.signatureMethod", "organization.SamlIdentityProvider.digestMethod",
+			"organization.SamlIdentityProvider.idpCertificate",
+		},
+		Evaluate: compareSamlProviderConfiguration,
+	})
+
+	registerRule(Rule{
+		ID:       "saml-idp-certificate",
+		Policy:   "SAML Signing Certificate",
+		Category: "account",
+		Severity: SeverityBlocker,
+		Inputs:   []string{"enterprise.SamlIdentityProvider.idpCertificate", "organization.SamlIdentityProvider.idpCertificate"},
+		Evaluate: func(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+			return compareSamlCertificates(org, ent, time.Now())
+		},
+	})
+}
+
+func compareSamlProviderConfiguration(org *OrganizationPolicies, ent *EnterprisePolicies) Finding {
+	orgProvider := org.GQL.Organization.SamlIdentityProvider
+	entProvider := ent.Enterprise.OwnerInfo.SamlIdentityProvider
+	finding := Finding{
+		SourceValue:    orgProvider.Issuer,
+		TargetValue:    entProvider.Issuer,
+		EffectiveValue: orgProvider.Issuer,
+	}
+
+	if entProvider.Id == "" {
+		finding.Comment = "SAML Single Sign On is not enabled at the Enterprise level. The Organization keeps its identity provider configuration."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.EffectiveValue = entProvider.Issuer
+
+	if orgProvider.Id == "" {
+		finding.Comment = "SAML Single Sign On is not enabled at the Organization level. The Enterprise identity provider configuration will apply to the Organization."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if orgProvider.Issuer != entProvider.Issuer {
+		finding.Comment = fmt.Sprintf("The Organization trusts the issuer %q, the Enterprise trusts %q. Identities linked with the Organization identity provider will not sign members in after the transfer.", orgProvider.Issuer, entProvider.Issuer)
+		finding.Status = statusFail
+		finding.Remediation = "Provision every member in the Enterprise identity provider, or move the Enterprise to the Organization's identity provider, before the transfer."
+		return finding
+	}
+
+	var differences []string
+	if orgProvider.SsoUrl != entProvider.SsoUrl {
+		differences = append(differences, fmt.Sprintf("SSO URL %q and %q", orgProvider.SsoUrl, entProvider.SsoUrl))
+	}
+	if samlAlgorithm(orgProvider.SignatureMethod) != samlAlgorithm(entProvider.SignatureMethod) {
+		differences = append(differences, fmt.Sprintf("signature method %s and %s", samlAlgorithm(orgProvider.SignatureMethod), samlAlgorithm(entProvider.SignatureMethod)))
+	}
+	if samlAlgorithm(orgProvider.DigestMethod) != samlAlgorithm(entProvider.DigestMethod) {
+		differences = append(differences, fmt.Sprintf("digest method %s and %s", samlAlgorithm(orgProvider.DigestMethod), samlAlgorithm(entProvider.DigestMethod)))
+	}
+	if samlCertificateValue(orgProvider) != samlCertificateValue(entProvider) {
+		differences = append(differences, "signing certificates")
+	}
+
+	if len(differences) == 0 {
+		finding.Comment = "The Organization and the Enterprise use the same identity provider configuration."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The Organization and the Enterprise trust the same issuer but have different %s. Sign in will follow the Enterprise configuration after the transfer.", strings.Join(differences, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Check that the Enterprise configuration matches the identity provider's current metadata before the transfer."
+
+	return finding
+}
+
+// samlCertificateValue identifies a signing certificate by its fingerprint, so that the same
+// certificate encoded differently compares equal.
+func samlCertificateValue(provider SamlIdentityProvider) string {
+	certificate, err := provider.certificate()
+	if err != nil || certificate == nil {
+		return provider.IdpCertificate
+	}
+
+	return samlCertificateFingerprint(certificate)
+}
+
+// samlCertificateExpiry describes when the signing certificate of a configuration expires.
+func samlCertificateExpiry(provider SamlIdentityProvider) string {
+	certificate, err := provider.certificate()
+	switch {
+	case err != nil:
+		return "invalid certificate"
+	case certificate == nil:
+		return ""
+	}
+
+	return "expires " + certificate.NotAfter.UTC().Format(time.RFC3339)
+}
+
+func compareSamlCertificates(org *OrganizationPolicies, ent *EnterprisePolicies, now time.Time) Finding {
+	orgProvider := org.GQL.Organization.SamlIdentityProvider
+	entProvider := ent.Enterprise.OwnerInfo.SamlIdentityProvider
+	finding := Finding{
+		SourceValue:    samlCertificateExpiry(orgProvider),
+		TargetValue:    samlCertificateExpiry(entProvider),
+		EffectiveValue: samlCertificateExpiry(orgProvider),
+	}
+	if entProvider.Id != "" {
+		finding.EffectiveValue = finding.TargetValue
+	}
+
+	var problems, invalid []string
+	for _, owner := range []struct {
+		name     string
+		provider SamlIdentityProvider
+	}{
+		{"Organization", orgProvider},
+		{"Enterprise", entProvider},
+	} {
+		// the Enterprise identity provider replaces the Organization's, whose certificate no longer matters
+		if owner.provider.Id == "" || owner.name == "Organization" && entProvider.Id != "" {
+			continue
+		}
+
+		certificate, err := owner.provider.certificate()
+		switch {
+		case err != nil:
+			invalid = append(invalid, fmt.Sprintf("The %s signing certificate could not be read: %s.", owner.name, err))
+		case certificate == nil:
+			invalid = append(invalid, fmt.Sprintf("The %s identity provider has no signing certificate.", owner.name))
+		case !now.Before(certificate.NotAfter):
+			problems = append(problems, fmt.Sprintf("The %s signing certificate expired on %s.", owner.name, certificate.NotAfter.UTC().Format("2006-01-02")))
+		case now.Add(samlCertificateExpiryWarning).After(certificate.NotAfter):
+			problems = append(problems, fmt.Sprintf("The %s signing certificate expires on %s.", owner.name, certificate.NotAfter.UTC().Format("2006-01-02")))
+		}
+	}
+
+	if orgProvider.Id == "" && entProvider.Id == "" {
+		finding.Comment = "SAML Single Sign On is not enabled at the Organization or the Enterprise level."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if len(problems) > 0 {
+		finding.Comment = strings.Join(append(problems, invalid...), " ")
+		finding.Status = statusFail
+		finding.Remediation = "Rotate the signing certificate in the identity provider and update the SAML configuration before the transfer."
+		return finding
+	}
+
+	if len(invalid) > 0 {
+		finding.Comment = strings.Join(invalid, " ")
+		finding.Status = statusUnknown
+		finding.Remediation = "Check the signing certificate in the SAML settings."
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("No signing certificate expires within %d days.", int(samlCertificateExpiryWarning.Hours()/24))
+	finding.Status = statusPass
+
+	return finding
+}
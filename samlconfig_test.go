@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testSamlCertificate returns a self-signed certificate expiring at notAfter, PEM encoded.
+func testSamlCertificate(t *testing.T, notAfter time.Time) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.octodemo.com"},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestSamlAlgorithm(t *testing.T) {
+	for method, want := range map[string]string{
+		"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256": "RSA_SHA256",
+		"http://www.w3.org/2000/09/xmldsig#sha1":            "SHA1",
+		"RSA_SHA256":                                        "RSA_SHA256",
+		"":                                                  "",
+	} {
+		if got := samlAlgorithm(method); got != want {
+			t.Errorf("samlAlgorithm(%q) = %q, want %q", method, got, want)
+		}
+	}
+}
+
+func TestCompareSamlProviderConfiguration(t *testing.T) {
+	certificate := testSamlCertificate(t, time.Now().AddDate(1, 0, 0))
+	block, _ := pem.Decode([]byte(certificate))
+
+	org := new(OrganizationPolicies)
+	org.GQL.Organization.SamlIdentityProvider = SamlIdentityProvider{
+		Id:              "org-idp",
+		Issuer:          "https://idp.octodemo.com",
+		SsoUrl:          "https://idp.octodemo.com/sso",
+		SignatureMethod: "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256",
+		DigestMethod:    "http://www.w3.org/2001/04/xmlenc#sha256",
+		IdpCertificate:  certificate,
+	}
+
+	ent := new(EnterprisePolicies)
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider = SamlIdentityProvider{
+		Id:              "ent-idp",
+		Issuer:          "https://idp.octodemo.com",
+		SsoUrl:          "https://idp.octodemo.com/sso",
+		SignatureMethod: "RSA_SHA256",
+		DigestMethod:    "SHA256",
+		IdpCertificate:  base64.StdEncoding.EncodeToString(block.Bytes),
+	}
+
+	if finding := compareSamlProviderConfiguration(org, ent); finding.Status != statusPass {
+		t.Errorf("expected the same configuration to pass, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.DigestMethod = "SHA1"
+	finding := compareSamlProviderConfiguration(org, ent)
+	want := "The Organization and the Enterprise trust the same issuer but have different digest method SHA256 and SHA1. Sign in will follow the Enterprise configuration after the transfer."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.Issuer = "https://sts.windows.net/tenant/"
+	finding = compareSamlProviderConfiguration(org, ent)
+	if finding.Status != statusFail || finding.EffectiveValue != "https://sts.windows.net/tenant/" {
+		t.Errorf("expected mismatched issuers to fail, got %+v", finding)
+	}
+}
+
+func TestCompareSamlCertificates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	org := new(OrganizationPolicies)
+	org.GQL.Organization.SamlIdentityProvider.Id = "org-idp"
+	org.GQL.Organization.SamlIdentityProvider.IdpCertificate = testSamlCertificate(t, now.AddDate(0, 0, -1))
+
+	ent := new(EnterprisePolicies)
+
+	finding := compareSamlCertificates(org, ent, now)
+	if finding.Status != statusFail || finding.Comment != "The Organization signing certificate expired on 2024-05-31." {
+		t.Errorf("expected the expired Organization certificate to fail without an Enterprise identity provider, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.Id = "ent-idp"
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.IdpCertificate = testSamlCertificate(t, now.AddDate(0, 0, 10))
+
+	finding = compareSamlCertificates(org, ent, now)
+	want := "The Enterprise signing certificate expires on 2024-06-11."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("expected only the Enterprise certificate to be checked, got %+v", finding)
+	}
+	if finding.EffectiveValue != "expires 2024-06-11T00:00:00Z" {
+		t.Errorf("expected the Enterprise certificate to be effective, got %q", finding.EffectiveValue)
+	}
+
+	org.GQL.Organization.SamlIdentityProvider = SamlIdentityProvider{}
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.IdpCertificate = testSamlCertificate(t, now.AddDate(1, 0, 0))
+	if finding := compareSamlCertificates(org, ent, now); finding.Status != statusPass {
+		t.Errorf("expected a valid certificate to pass, got %+v", finding)
+	}
+
+	ent.Enterprise.OwnerInfo.SamlIdentityProvider.IdpCertificate = "not a certificate"
+	if finding := compareSamlCertificates(org, ent, now); finding.Status != statusUnknown {
+		t.Errorf("expected an unreadable certificate to be unknown, got %+v", finding)
+	}
+}
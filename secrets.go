@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// secretStores are the organization secret and variable stores. key is the field of the REST list
+// response that holds the entries.
+var secretStores = []struct {
+	name string
+	path string
+	key  string
+}{
+	{"Actions secret", "actions/secrets", "secrets"},
+	{"Actions variable", "actions/variables", "variables"},
+	{"Dependabot secret", "dependabot/secrets", "secrets"},
+	{"Codespaces secret", "codespaces/secrets", "secrets"},
+}
+
+// OrganizationSecret is an organization secret or variable. Only its name is collected, never its value.
+// Visibility is "all", "private" or "selected"; SelectedRepositories is only set for "selected".
+type OrganizationSecret struct {
+	Store                string
+	Name                 string
+	Visibility           string
+	SelectedRepositories []string
+}
+
+// OrganizationSecrets are the secrets and variables of an organization and its internal repositories.
+type OrganizationSecrets struct {
+	Secrets              []OrganizationSecret
+	InternalRepositories []string
+}
+
+// getOrganizationSecrets lists the secrets and variables of every store of an organization. Stores
+// that are not available, such as Codespaces on some GitHub versions, are skipped.
+func getOrganizationSecrets(org string, repositories []Repository) (*OrganizationSecrets, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := new(OrganizationSecrets)
+
+	for _, store := range secretStores {
+		storeSecrets, err := getSecretStore(client, org, store.name, store.path, store.key)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		secrets.Secrets = append(secrets.Secrets, storeSecrets...)
+	}
+
+	for _, repository := range repositories {
+		if repository.Visibility == "internal" {
+			secrets.InternalRepositories = append(secrets.InternalRepositories, repository.Full_name)
+		}
+	}
+
+	return secrets, nil
+}
+
+// getSecretStore lists the entries of one store and the selected repositories of each. The entries
+// are decoded without their value, so variable values are never kept.
+func getSecretStore(client api.RESTClient, org string, store string, path string, key string) ([]OrganizationSecret, error) {
+	var secrets []OrganizationSecret
+
+	err := getAllPages(client, fmt.Sprintf("orgs/%s/%s?per_page=%d", org, path, restPageSize), func(body []byte) error {
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		var entries []struct {
+			Name       string
+			Visibility string
+		}
+		if err := json.Unmarshal(page[key], &entries); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			secrets = append(secrets, OrganizationSecret{Store: store, Name: entry.Name, Visibility: entry.Visibility})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, secret := range secrets {
+		if secret.Visibility != "selected" {
+			continue
+		}
+
+		err := getAllPages(client, fmt.Sprintf("orgs/%s/%s/%s/repositories?per_page=%d", org, path, secret.Name, restPageSize), func(body []byte) error {
+			var page struct {
+				Repositories []Repository
+			}
+			if err := json.Unmarshal(body, &page); err != nil {
+				return err
+			}
+
+			for _, repository := range page.Repositories {
+				secrets[i].SelectedRepositories = append(secrets[i].SelectedRepositories, repository.Full_name)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// reachesInternalRepositories tells whether workflows, Dependabot or codespaces of every internal
+// repository can use a secret. Secrets visible to private repositories are visible to internal ones too.
+// Secrets limited to selected repositories were scoped deliberately and are not counted.
+func reachesInternalRepositories(secret OrganizationSecret, internal []string) bool {
+	return len(internal) > 0 && (secret.Visibility == "all" || secret.Visibility == "private")
+}
+
+// secretImpact predicts whether the transfer widens who can reach a secret: once internal repositories
+// are readable by every enterprise member, so are the workflows that use the secrets they can reach.
+func secretImpact(secret OrganizationSecret, internal []string) string {
+	if !reachesInternalRepositories(secret, internal) {
+		return "no change"
+	}
+
+	return fmt.Sprintf("reachable from %d internal repositories", len(internal))
+}
+
+// secretsInventory lists the secrets and variables of an organization by name.
+func secretsInventory(org string, secrets *OrganizationSecrets) Inventory {
+	inventory := Inventory{
+		Name:    "Secrets and variables: " + org,
+		Columns: []string{"Store", "Name", "Visibility", "Selected Repositories", "After Transfer"},
+	}
+
+	for _, secret := range secrets.Secrets {
+		inventory.Rows = append(inventory.Rows, []string{
+			secret.Store,
+			secret.Name,
+			secret.Visibility,
+			strings.Join(secret.SelectedRepositories, ", "),
+			secretImpact(secret, secrets.InternalRepositories),
+		})
+	}
+
+	return inventory
+}
+
+var secretsVisibilityRule = Rule{
+	ID:       "secrets-internal-repositories",
+	Policy:   "Secrets Visible To Internal Repositories",
+	Category: "actions",
+	Severity: SeverityHigh,
+	Inputs:   []string{"organization.actions.secrets", "organization.actions.variables", "organization.dependabot.secrets", "organization.codespaces.secrets", "organization.repositories.internal"},
+	Scopes:   []string{"admin:org", "repo"},
+}
+
+// auditSecrets lists the secrets and variables of an organization and checks which of them internal
+// repositories can reach once every enterprise member can read those repositories.
+func auditSecrets(org string, repositories []Repository) ([]Finding, []Inventory) {
+	secrets, err := getOrganizationSecrets(org, repositories)
+	if err != nil {
+		return []Finding{uncollectedFinding(secretsVisibilityRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(secretsVisibilityRule, compareSecretsVisibility(secrets))
+
+	return []Finding{finding}, []Inventory{secretsInventory(org, secrets)}
+}
+
+func compareSecretsVisibility(secrets *OrganizationSecrets) Finding {
+	var exposed []string
+	for _, secret := range secrets.Secrets {
+		if reachesInternalRepositories(secret, secrets.InternalRepositories) {
+			exposed = append(exposed, fmt.Sprintf("%s %s (%s)", secret.Store, secret.Name, secret.Visibility))
+		}
+	}
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d secrets and variables, %d internal repositories", len(secrets.Secrets), len(secrets.InternalRepositories)),
+		EffectiveValue: fmt.Sprintf("%d reachable from internal repositories", len(exposed)),
+	}
+
+	if len(secrets.InternalRepositories) == 0 {
+		finding.Comment = "The Organization has no internal repositories. The transfer does not widen access to its secrets and variables."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if len(exposed) == 0 {
+		finding.Comment = "Every secret and variable of the Organization is limited to selected repositories."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The %s are available to every internal repository. Every Enterprise member will be able to read these repositories and the workflows that use them.", strings.Join(exposed, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Limit these secrets and variables to selected repositories before the transfer."
+
+	return finding
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestCompareSecretsVisibility(t *testing.T) {
+	secrets := &OrganizationSecrets{
+		Secrets: []OrganizationSecret{
+			{Store: "Actions secret", Name: "NPM_TOKEN", Visibility: "all"},
+			{Store: "Actions variable", Name: "REGISTRY", Visibility: "private"},
+			{Store: "Dependabot secret", Name: "MAVEN_PASSWORD", Visibility: "selected", SelectedRepositories: []string{"octodemo/api"}},
+		},
+	}
+
+	if finding := compareSecretsVisibility(secrets); finding.Status != statusPass {
+		t.Errorf("expected no impact without internal repositories, got %+v", finding)
+	}
+
+	secrets.InternalRepositories = []string{"octodemo/handbook", "octodemo/payroll"}
+
+	finding := compareSecretsVisibility(secrets)
+	want := "The Actions secret NPM_TOKEN (all), Actions variable REGISTRY (private) are available to every internal repository. Every Enterprise member will be able to read these repositories and the workflows that use them."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	rows := secretsInventory("octodemo", secrets).Rows
+	if rows[0][4] != "reachable from 2 internal repositories" || rows[2][3] != "octodemo/api" || rows[2][4] != "no change" {
+		t.Errorf("unexpected inventory %v", rows)
+	}
+}
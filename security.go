@@ -0,0 +1,484 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// SecurityFeature is the status of a security feature of a repository, "enabled" or "disabled".
+type SecurityFeature struct {
+	Status string
+}
+
+// SecurityAndAnalysis are the security features of a repository. The REST API only returns them to
+// repository admins.
+type SecurityAndAnalysis struct {
+	Advanced_security               SecurityFeature
+	Secret_scanning                 SecurityFeature
+	Secret_scanning_push_protection SecurityFeature
+	Dependabot_security_updates     SecurityFeature
+}
+
+// RepositorySecurity is the security coverage of a repository. CodeScanningDefaultSetup is
+// "configured", "not-configured", "not readable" when the token was refused, or "" when the repository
+// cannot use code scanning.
+type RepositorySecurity struct {
+	Repository               Repository
+	CodeScanningDefaultSetup string
+}
+
+// EnterpriseSecurityDefaults are the security features an enterprise enables for new repositories.
+// A nil field is a feature the enterprise does not report.
+type EnterpriseSecurityDefaults struct {
+	Advanced_security_enabled_for_new_repositories               *bool
+	Dependabot_alerts_enabled_for_new_repositories               *bool
+	Dependabot_security_updates_enabled_for_new_repositories     *bool
+	Secret_scanning_enabled_for_new_repositories                 *bool
+	Secret_scanning_push_protection_enabled_for_new_repositories *bool
+}
+
+// AdvancedSecurityCommitters is the GitHub Advanced Security license usage of an organization or an
+// enterprise. Purchased and maximum committers are 0 when the licenses are billed by usage.
+type AdvancedSecurityCommitters struct {
+	Total_advanced_security_committers     int
+	Purchased_advanced_security_committers int
+	Maximum_advanced_security_committers   int
+	Repositories                           []struct {
+		Name                                   string
+		Advanced_security_committers_breakdown []struct {
+			User_login string
+		}
+	}
+}
+
+// committers lists the logins of every committer counted against the licenses.
+func (usage *AdvancedSecurityCommitters) committers() map[string]bool {
+	logins := make(map[string]bool)
+	if usage == nil {
+		return logins
+	}
+
+	for _, repository := range usage.Repositories {
+		for _, committer := range repository.Advanced_security_committers_breakdown {
+			logins[committer.User_login] = true
+		}
+	}
+
+	return logins
+}
+
+// seats is how many committers the licenses cover, or 0 when they are billed by usage.
+func (usage *AdvancedSecurityCommitters) seats() int {
+	if usage.Purchased_advanced_security_committers > 0 {
+		return usage.Purchased_advanced_security_committers
+	}
+
+	return usage.Maximum_advanced_security_committers
+}
+
+// OrganizationSecurity is the security coverage of the repositories of an organization and its
+// Advanced Security license usage. Committers is nil when the organization has no licenses.
+type OrganizationSecurity struct {
+	Repositories []RepositorySecurity
+	Committers   *AdvancedSecurityCommitters
+}
+
+// EnterpriseSecurity is the security configuration of an enterprise. Defaults and Committers are nil
+// when the enterprise does not report them. Committers is also nil when the token cannot read the
+// enterprise billing, which GitHub does not tell apart from an enterprise without licenses.
+type EnterpriseSecurity struct {
+	Defaults   *EnterpriseSecurityDefaults
+	Committers *AdvancedSecurityCommitters
+}
+
+// getOrganizationSecurity lists the security features of every repository of an organization and
+// its Advanced Security committers.
+func getOrganizationSecurity(org string, repositories []Repository) (*OrganizationSecurity, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	security := new(OrganizationSecurity)
+
+	for _, repository := range repositories {
+		var setup struct {
+			State string
+		}
+
+		// code scanning is not available on archived repositories, or private ones without Advanced Security.
+		// A refused request, for example without the security_events scope, leaves the setup unknown.
+		err := client.Get(fmt.Sprintf("repos/%s/code-scanning/default-setup", repository.Full_name), &setup)
+		switch {
+		case isForbidden(err):
+			setup.State = "not readable"
+		case err != nil && !isNotFound(err):
+			return nil, err
+		}
+
+		security.Repositories = append(security.Repositories, RepositorySecurity{Repository: repository, CodeScanningDefaultSetup: setup.State})
+	}
+
+	if security.Committers, err = getAdvancedSecurityCommitters(client, "orgs/"+org); err != nil {
+		return nil, err
+	}
+
+	return security, nil
+}
+
+// getEnterpriseSecurity collects the security defaults and Advanced Security license usage of an enterprise.
+func getEnterpriseSecurity(ent string) (*EnterpriseSecurity, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	security := new(EnterpriseSecurity)
+
+	defaults := new(EnterpriseSecurityDefaults)
+	found, err := getOptional(client, fmt.Sprintf("enterprises/%s/code_security_and_analysis", ent), defaults)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		security.Defaults = defaults
+	}
+
+	if security.Committers, err = getAdvancedSecurityCommitters(client, "enterprises/"+ent); err != nil {
+		return nil, err
+	}
+
+	return security, nil
+}
+
+// getAdvancedSecurityCommitters requests the Advanced Security license usage under an orgs/ or
+// enterprises/ REST path. It returns nil when there are no Advanced Security licenses or the token may not
+// read them.
+func getAdvancedSecurityCommitters(client api.RESTClient, owner string) (*AdvancedSecurityCommitters, error) {
+	var usage *AdvancedSecurityCommitters
+
+	err := getAllPages(client, fmt.Sprintf("%s/settings/billing/advanced-security?per_page=%d", owner, restPageSize), func(body []byte) error {
+		page := new(AdvancedSecurityCommitters)
+		if err := json.Unmarshal(body, page); err != nil {
+			return err
+		}
+
+		if usage == nil {
+			usage = page
+		} else {
+			usage.Repositories = append(usage.Repositories, page.Repositories...)
+		}
+
+		return nil
+	})
+
+	if isPermissionError(err) {
+		return nil, nil
+	}
+
+	return usage, err
+}
+
+// security returns the security features of a repository, with "not readable" statuses when they were
+// not returned to the token.
+func (repository Repository) security() SecurityAndAnalysis {
+	if repository.Security_and_analysis == nil {
+		unreadable := SecurityFeature{"not readable"}
+		return SecurityAndAnalysis{unreadable, unreadable, unreadable, unreadable}
+	}
+
+	return *repository.Security_and_analysis
+}
+
+// usesAdvancedSecurity tells whether a private or internal repository is counted against the Advanced
+// Security licenses. Code scanning default setup needs Advanced Security on these repositories, so it
+// reveals the licenses even when the token may not read the security features.
+func (repository RepositorySecurity) usesAdvancedSecurity() bool {
+	if repository.Repository.Visibility == "public" {
+		return false
+	}
+
+	return repository.Repository.security().Advanced_security.Status == "enabled" || repository.CodeScanningDefaultSetup == "configured"
+}
+
+// repositorySecurityInventory lists the security features of the repositories of an organization.
+func repositorySecurityInventory(org string, security *OrganizationSecurity) Inventory {
+	inventory := Inventory{
+		Name:    "Repository security: " + org,
+		Columns: []string{"Repository", "Visibility", "Advanced Security", "Secret Scanning", "Push Protection", "Dependabot Security Updates", "Code Scanning Default Setup"},
+	}
+
+	for _, repository := range security.Repositories {
+		inventory.Rows = append(inventory.Rows, []string{
+			repository.Repository.Full_name,
+			repository.Repository.Visibility,
+			repository.Repository.security().Advanced_security.Status,
+			repository.Repository.security().Secret_scanning.Status,
+			repository.Repository.security().Secret_scanning_push_protection.Status,
+			repository.Repository.security().Dependabot_security_updates.Status,
+			repository.CodeScanningDefaultSetup,
+		})
+	}
+
+	return inventory
+}
+
+// advancedSecuritySettings flattens Advanced Security license usage into named settings.
+func advancedSecuritySettings(usage *AdvancedSecurityCommitters) []PolicySetting {
+	if usage == nil {
+		return []PolicySetting{{"AdvancedSecurityLicenses", "none"}}
+	}
+
+	seats := "billed by usage"
+	if usage.seats() > 0 {
+		seats = strconv.Itoa(usage.seats())
+	}
+
+	return []PolicySetting{
+		{"AdvancedSecurityCommitters", strconv.Itoa(usage.Total_advanced_security_committers)},
+		{"AdvancedSecuritySeats", seats},
+	}
+}
+
+// enterpriseSecuritySettings flattens the security configuration of an enterprise into named settings.
+func enterpriseSecuritySettings(security *EnterpriseSecurity) []PolicySetting {
+	var settings []PolicySetting
+
+	if defaults := security.Defaults; defaults != nil {
+		for _, setting := range []struct {
+			key   string
+			value *bool
+		}{
+			{"AdvancedSecurityEnabledForNewRepositories", defaults.Advanced_security_enabled_for_new_repositories},
+			{"DependabotAlertsEnabledForNewRepositories", defaults.Dependabot_alerts_enabled_for_new_repositories},
+			{"DependabotSecurityUpdatesEnabledForNewRepositories", defaults.Dependabot_security_updates_enabled_for_new_repositories},
+			{"SecretScanningEnabledForNewRepositories", defaults.Secret_scanning_enabled_for_new_repositories},
+			{"SecretScanningPushProtectionEnabledForNewRepositories", defaults.Secret_scanning_push_protection_enabled_for_new_repositories},
+		} {
+			if setting.value != nil {
+				settings = append(settings, PolicySetting{setting.key, strconv.FormatBool(*setting.value)})
+			}
+		}
+	}
+
+	return append(settings, advancedSecuritySettings(security.Committers)...)
+}
+
+var securityDefaultsRule = Rule{
+	ID:       "security-defaults",
+	Policy:   "Security Features For New Repositories",
+	Category: "security",
+	Severity: SeverityMedium,
+	Inputs: []string{
+		"enterprise.code_security_and_analysis",
+		"organization.Advanced_security_enabled_for_new_repositories", "organization.Dependabot_alerts_enabled_for_new_repositories",
+		"organization.Dependabot_security_updates_enabled_for_new_repositories",
+		"organization.Secret_scanning_enabled_for_new_repositories", "organization.Secret_scanning_push_protection_enabled_for_new_repositories",
+	},
+	Scopes: []string{"admin:org", "repo", "read:enterprise"},
+}
+
+var advancedSecurityLicensesRule = Rule{
+	ID:       "advanced-security-licenses",
+	Policy:   "Advanced Security Licenses",
+	Category: "security",
+	Severity: SeverityHigh,
+	Inputs:   []string{"enterprise.settings.billing.advanced-security", "organization.settings.billing.advanced-security", "organization.repositories.security_and_analysis"},
+	Scopes:   []string{"admin:org", "repo", "security_events", "manage_billing:enterprise"},
+}
+
+// auditSecurity lists the security coverage of an organization and checks that the enterprise security
+// configuration and Advanced Security licenses keep it.
+func auditSecurity(org string, repositories []Repository, orgPolicies *OrganizationPolicies, ent string) ([]Finding, []Inventory) {
+	uncollected := func(err error) ([]Finding, []Inventory) {
+		return []Finding{uncollectedFinding(securityDefaultsRule, err), uncollectedFinding(advancedSecurityLicensesRule, err)}, nil
+	}
+
+	orgSecurity, err := getOrganizationSecurity(org, repositories)
+	if err != nil {
+		return uncollected(err)
+	}
+
+	entSecurity, err := getEnterpriseSecurity(ent)
+	if err != nil {
+		return uncollected(err)
+	}
+
+	findings := []Finding{
+		withRuleMetadata(securityDefaultsRule, compareSecurityDefaults(orgPolicies, entSecurity)),
+		withRuleMetadata(advancedSecurityLicensesRule, compareAdvancedSecurityLicenses(orgSecurity, entSecurity)),
+	}
+
+	return findings, []Inventory{
+		repositorySecurityInventory(org, orgSecurity),
+		settingsInventory("Advanced Security licenses: "+org, advancedSecuritySettings(orgSecurity.Committers)),
+		settingsInventory("Security configuration: "+ent, enterpriseSecuritySettings(entSecurity)),
+	}
+}
+
+func compareSecurityDefaults(org *OrganizationPolicies, ent *EnterpriseSecurity) Finding {
+	finding := Finding{}
+
+	if ent.Defaults == nil {
+		finding.Comment = "The Enterprise does not report the security features it enables for new repositories."
+		finding.Status = statusUnknown
+		finding.Remediation = "Compare the code security and analysis settings of the Enterprise with the Organization's."
+		return finding
+	}
+
+	var enabled, dropped, unreported []string
+	for _, feature := range []struct {
+		name string
+		org  *bool
+		ent  *bool
+	}{
+		{"Advanced Security", org.REST.Advanced_security_enabled_for_new_repositories, ent.Defaults.Advanced_security_enabled_for_new_repositories},
+		{"Dependabot alerts", org.REST.Dependabot_alerts_enabled_for_new_repositories, ent.Defaults.Dependabot_alerts_enabled_for_new_repositories},
+		{"Dependabot security updates", org.REST.Dependabot_security_updates_enabled_for_new_repositories, ent.Defaults.Dependabot_security_updates_enabled_for_new_repositories},
+		{"secret scanning", org.REST.Secret_scanning_enabled_for_new_repositories, ent.Defaults.Secret_scanning_enabled_for_new_repositories},
+		{"push protection", org.REST.Secret_scanning_push_protection_enabled_for_new_repositories, ent.Defaults.Secret_scanning_push_protection_enabled_for_new_repositories},
+	} {
+		if feature.org == nil {
+			unreported = append(unreported, feature.name)
+			continue
+		}
+		if !*feature.org {
+			continue
+		}
+
+		enabled = append(enabled, feature.name)
+		if feature.ent != nil && !*feature.ent {
+			dropped = append(dropped, feature.name)
+		}
+	}
+
+	finding.SourceValue = strings.Join(enabled, ", ")
+	finding.TargetValue = fmt.Sprintf("%d of %d disabled", len(dropped), len(enabled))
+	finding.EffectiveValue = finding.SourceValue
+
+	if len(dropped) == 0 && len(unreported) > 0 {
+		finding.Comment = fmt.Sprintf("The Organization did not report whether it enables %s for new repositories. It may require Organization owner access.", strings.Join(unreported, ", "))
+		finding.Status = statusUnknown
+		return finding
+	}
+
+	if len(dropped) == 0 {
+		finding.Comment = "The Enterprise enables every security feature the Organization enables for new repositories."
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The Organization enables %s for new repositories, the Enterprise does not. Coverage will drop if the Organization follows the Enterprise defaults.", strings.Join(dropped, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Keep these features enabled for new repositories in the Organization settings after the transfer, or enable them in the Enterprise."
+
+	return finding
+}
+
+func compareAdvancedSecurityLicenses(org *OrganizationSecurity, ent *EnterpriseSecurity) Finding {
+	var covered, scanned, unreadable []string
+	for _, repository := range org.Repositories {
+		switch {
+		case repository.usesAdvancedSecurity():
+			covered = append(covered, repository.Repository.Full_name)
+			switch repository.CodeScanningDefaultSetup {
+			case "configured":
+				scanned = append(scanned, repository.Repository.Full_name)
+			case "not readable":
+				// whether code scanning default setup stops with the licenses is unknown
+				unreadable = append(unreadable, repository.Repository.Full_name)
+			}
+		case repository.Repository.Visibility != "public" && repository.Repository.Security_and_analysis == nil:
+			unreadable = append(unreadable, repository.Repository.Full_name)
+		}
+	}
+
+	entCommitters := ent.Committers.committers()
+	var newCommitters []string
+	for login := range org.Committers.committers() {
+		if !entCommitters[login] {
+			newCommitters = append(newCommitters, login)
+		}
+	}
+	sort.Strings(newCommitters)
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d repositories (%d with code scanning default setup), %d new committers", len(covered), len(scanned), len(newCommitters)),
+		EffectiveValue: fmt.Sprintf("%d repositories with Advanced Security", len(covered)),
+	}
+
+	// code scanning default setup stops with the licenses, so it is named wherever they fall short
+	var scanning string
+	if len(scanned) > 0 {
+		scanning = fmt.Sprintf(" Code scanning default setup will stop on %s.", strings.Join(scanned, ", "))
+	}
+
+	if len(covered) == 0 && len(unreadable) > 0 {
+		finding.Comment = fmt.Sprintf("The token may not read the security features or code scanning default setup of %s. Whether they use Advanced Security is unknown.", strings.Join(unreadable, ", "))
+		finding.Status = statusUnknown
+		finding.Remediation = unreadableSecurityRemediation
+		return finding
+	}
+
+	if len(covered) == 0 {
+		finding.Comment = "No private or internal repository of the Organization uses Advanced Security."
+		finding.Status = statusPass
+		return finding
+	}
+
+	if ent.Committers == nil {
+		finding.TargetValue = "not reported"
+		finding.Comment = fmt.Sprintf("The Enterprise did not report Advanced Security licenses. It has none, or the token may not read its billing. Without licenses the repositories %s will lose Advanced Security.%s", strings.Join(covered, ", "), scanning)
+		finding.Status = statusUnknown
+		finding.Remediation = "Check the Advanced Security licenses of the Enterprise with a token that has the manage_billing:enterprise scope, and purchase them before the transfer if there are none."
+		return finding
+	}
+
+	seats := ent.Committers.seats()
+	if seats == 0 {
+		finding.TargetValue = "billed by usage"
+		finding.Comment = fmt.Sprintf("The Enterprise bills Advanced Security by usage. The %d committers of the Organization not yet counted by the Enterprise will be billed.", len(newCommitters))
+		finding.Status = statusPass
+		return passUnlessUnreadable(finding, unreadable)
+	}
+
+	available := seats - ent.Committers.Total_advanced_security_committers
+	finding.TargetValue = fmt.Sprintf("%d of %d seats available", available, seats)
+
+	if len(newCommitters) <= available {
+		finding.Comment = fmt.Sprintf("The Enterprise has %d Advanced Security seats available for the %d committers of the Organization it does not count yet.", available, len(newCommitters))
+		finding.Status = statusPass
+		return passUnlessUnreadable(finding, unreadable)
+	}
+
+	finding.EffectiveValue = "licenses exceeded"
+	finding.Comment = fmt.Sprintf("The Organization brings %d Advanced Security committers the Enterprise does not count yet, but the Enterprise licenses only cover %d more. Advanced Security cannot stay enabled on every one of %s.%s", len(newCommitters), available, strings.Join(covered, ", "), scanning)
+	finding.Status = statusFail
+	finding.Remediation = fmt.Sprintf("Purchase %d more Advanced Security seats for the Enterprise, or disable Advanced Security on some repositories, before the transfer.", len(newCommitters)-available)
+
+	return finding
+}
+
+// unreadableSecurityRemediation is the remediation when the REST API did not return the security features of
+// some repositories, which it only does to repository admins, or refused their code scanning default setup.
+const unreadableSecurityRemediation = "Run the audit as an owner of the Organization with a token that has the security_events scope, so the security features of every repository are returned."
+
+// passUnlessUnreadable turns a passing license finding unknown when the security features or code scanning
+// default setup of some private or internal repositories could not be read, as they may need more seats.
+func passUnlessUnreadable(finding Finding, unreadable []string) Finding {
+	if len(unreadable) == 0 {
+		return finding
+	}
+
+	finding.Comment += fmt.Sprintf(" The token may not read the security features or code scanning default setup of %s, so the seats they need are not fully known.", strings.Join(unreadable, ", "))
+	finding.Status = statusUnknown
+	finding.Remediation = unreadableSecurityRemediation
+
+	return finding
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCompareSecurityDefaults(t *testing.T) {
+	enabled, disabled := true, false
+	org := new(OrganizationPolicies)
+	org.REST.Advanced_security_enabled_for_new_repositories = &disabled
+	org.REST.Dependabot_alerts_enabled_for_new_repositories = &enabled
+	org.REST.Dependabot_security_updates_enabled_for_new_repositories = &enabled
+	org.REST.Secret_scanning_enabled_for_new_repositories = &enabled
+	org.REST.Secret_scanning_push_protection_enabled_for_new_repositories = &enabled
+
+	if finding := compareSecurityDefaults(org, &EnterpriseSecurity{}); finding.Status != statusUnknown {
+		t.Errorf("expected unknown without Enterprise defaults, got %+v", finding)
+	}
+
+	ent := &EnterpriseSecurity{Defaults: &EnterpriseSecurityDefaults{
+		Dependabot_alerts_enabled_for_new_repositories:               &enabled,
+		Dependabot_security_updates_enabled_for_new_repositories:     &disabled,
+		Secret_scanning_enabled_for_new_repositories:                 &disabled,
+		Secret_scanning_push_protection_enabled_for_new_repositories: &disabled,
+	}}
+
+	finding := compareSecurityDefaults(org, ent)
+	want := "The Organization enables Dependabot security updates, secret scanning, push protection for new repositories, the Enterprise does not. Coverage will drop if the Organization follows the Enterprise defaults."
+	if finding.Status != statusFail || finding.Comment != want || finding.TargetValue != "3 of 4 disabled" {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	if finding := compareSecurityDefaults(new(OrganizationPolicies), ent); finding.Status != statusUnknown {
+		t.Errorf("expected unknown when the Organization does not report its defaults, got %+v", finding)
+	}
+}
+
+func TestCompareAdvancedSecurityLicenses(t *testing.T) {
+	usage := func(body string) *AdvancedSecurityCommitters {
+		committers := new(AdvancedSecurityCommitters)
+		if err := json.Unmarshal([]byte(body), committers); err != nil {
+			t.Fatal(err)
+		}
+		return committers
+	}
+
+	enabled := &SecurityAndAnalysis{Advanced_security: SecurityFeature{"enabled"}}
+	org := &OrganizationSecurity{
+		Repositories: []RepositorySecurity{
+			{Repository: Repository{Full_name: "octodemo/api", Visibility: "private", Security_and_analysis: enabled}},
+			{Repository: Repository{Full_name: "octodemo/docs", Visibility: "public", Security_and_analysis: enabled}},
+		},
+		Committers: usage(`{"total_advanced_security_committers": 3, "repositories": [{"name": "octodemo/api", "advanced_security_committers_breakdown": [{"user_login": "hubot"}, {"user_login": "monalisa"}, {"user_login": "octocat"}]}]}`),
+	}
+
+	ent := &EnterpriseSecurity{
+		Committers: usage(`{"total_advanced_security_committers": 9, "purchased_advanced_security_committers": 10, "repositories": [{"name": "octo-ent/app", "advanced_security_committers_breakdown": [{"user_login": "monalisa"}]}]}`),
+	}
+
+	finding := compareAdvancedSecurityLicenses(org, ent)
+	want := "The Organization brings 2 Advanced Security committers the Enterprise does not count yet, but the Enterprise licenses only cover 1 more. Advanced Security cannot stay enabled on every one of octodemo/api."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	ent.Committers.Purchased_advanced_security_committers = 20
+	if finding := compareAdvancedSecurityLicenses(org, ent); finding.Status != statusPass {
+		t.Errorf("expected enough seats to pass, got %+v", finding)
+	}
+
+	org.Repositories[0].CodeScanningDefaultSetup = "not readable"
+	if finding := compareAdvancedSecurityLicenses(org, ent); finding.Status != statusUnknown {
+		t.Errorf("expected an unreadable code scanning default setup to be unknown, got %+v", finding)
+	}
+	org.Repositories[0].CodeScanningDefaultSetup = ""
+
+	if finding := compareAdvancedSecurityLicenses(org, &EnterpriseSecurity{}); finding.Status != statusUnknown || finding.TargetValue != "not reported" {
+		t.Errorf("expected unreported Enterprise licenses to be unknown, got %+v", finding)
+	}
+
+	org.Repositories = append(org.Repositories, RepositorySecurity{Repository: Repository{Full_name: "octodemo/payroll", Visibility: "internal"}})
+	if finding := compareAdvancedSecurityLicenses(org, ent); finding.Status != statusUnknown || finding.SourceValue != "1 repositories (0 with code scanning default setup), 2 new committers" {
+		t.Errorf("expected unreadable security features to be unknown, got %+v", finding)
+	}
+
+	org.Repositories[2].CodeScanningDefaultSetup = "configured"
+	ent.Committers.Purchased_advanced_security_committers = 10
+	finding = compareAdvancedSecurityLicenses(org, ent)
+	want = "The Organization brings 2 Advanced Security committers the Enterprise does not count yet, but the Enterprise licenses only cover 1 more. Advanced Security cannot stay enabled on every one of octodemo/api, octodemo/payroll. Code scanning default setup will stop on octodemo/payroll."
+	if finding.Status != statusFail || finding.Comment != want {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	rows := repositorySecurityInventory("octodemo", org).Rows
+	if rows[0][2] != "enabled" || rows[0][3] != "" || rows[2][2] != "not readable" || rows[2][6] != "configured" {
+		t.Errorf("unexpected inventory %v", rows)
+	}
+}
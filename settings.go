@@ -0,0 +1,94 @@
+package main
+
+import "strconv"
+
+// PolicySetting is a single named policy value, keyed by the names the table printers show.
+type PolicySetting struct {
+	Key   string
+	Value string
+}
+
+// organizationSettings flattens the GraphQL and REST policies of an organization into named settings.
+func organizationSettings(orgPolicies OrganizationPolicies) []PolicySetting {
+	rest := orgPolicies.REST
+	gql := orgPolicies.GQL.Organization
+
+	return []PolicySetting{
+		{"HasOrganizationProjects", strconv.FormatBool(rest.Has_organization_projects)},
+		{"HasRepositoryProjects", strconv.FormatBool(rest.Has_repository_projects)},
+		{"DefaultRepositoryPermission", rest.Default_repository_permission},
+		{"MembersCanCreateRepositories", optionalBool(rest.Members_can_create_repositories)},
+		{"TwoFactorRequirementEnabled", optionalBool(rest.Two_factor_requirement_enabled)},
+		{"MembersAllowedRepositoryCreationType", rest.Members_allowed_repository_creation_type},
+		{"MembersCanCreatePublicRepositories", optionalBool(rest.Members_can_create_public_repositories)},
+		{"MembersCanCreatePrivateRepositories", optionalBool(rest.Members_can_create_private_repositories)},
+		{"MembersCanCreateInternalRepositories", optionalBool(rest.Members_can_create_internal_repositories)},
+		{"MembersCanCreatePages", optionalBool(rest.Members_can_create_pages)},
+		{"MembersCanForkPrivateRepositoriesREST", optionalBool(rest.Members_can_fork_private_repositories)},
+		{"IpAllowListEnabledSetting", gql.IpAllowListEnabledSetting},
+		{"IpAllowListEntries", gql.IpAllowListEntries.values()},
+		{"IpAllowListForInstalledAppsEnabledSetting", gql.IpAllowListForInstalledAppsEnabledSetting},
+		{"MembersCanForkPrivateRepositories", strconv.FormatBool(gql.MembersCanForkPrivateRepositories)},
+		{"NotificationDeliveryRestrictionEnabledSetting", gql.NotificationDeliveryRestrictionEnabledSetting},
+		{"RequiresTwoFactorAuthentication", strconv.FormatBool(gql.RequiresTwoFactorAuthentication)},
+		{"SamlIdentityProvider", gql.SamlIdentityProvider.Id},
+		{"AdvancedSecurityEnabledForNewRepositories", optionalBool(rest.Advanced_security_enabled_for_new_repositories)},
+		{"DependabotAlertsEnabledForNewRepositories", optionalBool(rest.Dependabot_alerts_enabled_for_new_repositories)},
+		{"DependabotSecurityUpdatesEnabledForNewRepositories", optionalBool(rest.Dependabot_security_updates_enabled_for_new_repositories)},
+		{"DependencyGraphEnabledForNewRepositories", optionalBool(rest.Dependency_graph_enabled_for_new_repositories)},
+		{"SecretScanningEnabledForNewRepositories", optionalBool(rest.Secret_scanning_enabled_for_new_repositories)},
+		{"SecretScanningPushProtectionEnabledForNewRepositories", optionalBool(rest.Secret_scanning_push_protection_enabled_for_new_repositories)},
+	}
+}
+
+// optionalBool formats a boolean that may not have been returned, leaving it empty when it was not.
+func optionalBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*value)
+}
+
+// enterpriseSettings flattens the owner info policies of an enterprise into named settings.
+func enterpriseSettings(entPolicies EnterprisePolicies) []PolicySetting {
+	ownerInfo := entPolicies.Enterprise.OwnerInfo
+
+	return []PolicySetting{
+		{"AllowPrivateRepositoryForkingSetting", ownerInfo.AllowPrivateRepositoryForkingSetting},
+		{"AllowPrivateRepositoryForkingSettingPolicyValue", ownerInfo.AllowPrivateRepositoryForkingSettingPolicyValue},
+		{"DefaultRepositoryPermissionSetting", ownerInfo.DefaultRepositoryPermissionSetting},
+		{"IpAllowListEnabledSetting", ownerInfo.IpAllowListEnabledSetting},
+		{"IpAllowListEntries", ownerInfo.IpAllowListEntries.values()},
+		{"IpAllowListForInstalledAppsEnabledSetting", ownerInfo.IpAllowListForInstalledAppsEnabledSetting},
+		{"MembersCanChangeRepositoryVisibilitySetting", ownerInfo.MembersCanChangeRepositoryVisibilitySetting},
+		{"MembersCanCreateRepositoriesSetting", ownerInfo.MembersCanCreateRepositoriesSetting},
+		{"MembersCanDeleteIssuesSetting", ownerInfo.MembersCanDeleteIssuesSetting},
+		{"MembersCanDeleteRepositoriesSetting", ownerInfo.MembersCanDeleteRepositoriesSetting},
+		{"MembersCanInviteCollaboratorsSetting", ownerInfo.MembersCanInviteCollaboratorsSetting},
+		{"MembersCanMakePurchasesSetting", ownerInfo.MembersCanMakePurchasesSetting},
+		{"MembersCanUpdateProtectedBranchesSetting", ownerInfo.MembersCanUpdateProtectedBranchesSetting},
+		{"MembersCanViewDependencyInsightsSetting", ownerInfo.MembersCanViewDependencyInsightsSetting},
+		{"NotificationDeliveryRestrictionEnabledSetting", ownerInfo.NotificationDeliveryRestrictionEnabledSetting},
+		{"OrganizationProjectsSetting", ownerInfo.OrganizationProjectsSetting},
+		{"RepositoryProjectsSetting", ownerInfo.RepositoryProjectsSetting},
+		{"SamlIdentityProvider", ownerInfo.SamlIdentityProvider.Id},
+		{"TeamDiscussionsSetting", ownerInfo.TeamDiscussionsSetting},
+		{"TwoFactorRequiredSetting", ownerInfo.TwoFactorRequiredSetting},
+	}
+}
+
+// settingsInventory lists named settings as an inventory, for settings collected outside
+// OrganizationPolicies and EnterprisePolicies.
+func settingsInventory(name string, settings []PolicySetting) Inventory {
+	inventory := Inventory{
+		Name:    name,
+		Columns: []string{"Policy Name", "Policy Value"},
+	}
+
+	for _, setting := range settings {
+		inventory.Rows = append(inventory.Rows, []string{setting.Key, setting.Value})
+	}
+
+	return inventory
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks how much a finding affects a transfer. The zero value means the severity was not set.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityBlocker
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:    "info",
+	SeverityLow:     "low",
+	SeverityMedium:  "medium",
+	SeverityHigh:    "high",
+	SeverityBlocker: "blocker",
+}
+
+// severityWeights is how many points a failed finding of each severity takes off the readiness score.
+var severityWeights = map[Severity]int{
+	SeverityInfo:    0,
+	SeverityLow:     2,
+	SeverityMedium:  5,
+	SeverityHigh:    10,
+	SeverityBlocker: 25,
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// parseSeverity converts a severity name such as "high" into a Severity.
+func parseSeverity(name string) (Severity, error) {
+	for severity, severityName := range severityNames {
+		if strings.EqualFold(name, severityName) {
+			return severity, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown severity %q", name)
+}
+
+// Readiness summarizes the findings for one organization into a transfer readiness score out of 100.
+type Readiness struct {
+	Organization string
+	Score        int
+	Failed       int
+	Unknown      int
+	Blockers     int
+}
+
+// Ready reports whether the organization can be transferred without resolving a blocker first.
+func (r Readiness) Ready() bool {
+	return r.Blockers == 0
+}
+
+func (r Readiness) String() string {
+	verdict := "ready"
+	if !r.Ready() {
+		verdict = "not ready"
+	}
+
+	return fmt.Sprintf("Transfer readiness for %s: %d/100 (%s, %d failed, %d blockers, %d unknown)", r.Organization, r.Score, verdict, r.Failed, r.Blockers, r.Unknown)
+}
+
+// transferReadiness scores findings by subtracting the weight of every failed finding from 100.
+// Findings with an unknown status are counted but do not change the score.
+func transferReadiness(organization string, findings []Finding) Readiness {
+	readiness := Readiness{Organization: organization, Score: 100}
+
+	for _, finding := range findings {
+		switch finding.Status {
+		case statusFail:
+			readiness.Failed++
+			readiness.Score -= severityWeights[finding.Severity]
+			if finding.Severity == SeverityBlocker {
+				readiness.Blockers++
+			}
+		case statusUnknown:
+			readiness.Unknown++
+		}
+	}
+
+	if readiness.Score < 0 {
+		readiness.Score = 0
+	}
+
+	return readiness
+}
+
+// MarshalText encodes a severity by name so it reads as "high" rather than a number in JSON output.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name such as "high".
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := parseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = severity
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// Webhook is an organization or repository webhook. Scope is "organization" or the full name of the
+// repository. The secret is only reported as set or not, the API masks its value.
+type Webhook struct {
+	Id     int64
+	Active bool
+	Events []string
+	Config struct {
+		Url          string
+		Content_type string
+		Secret       string
+	}
+	Scope        string           `json:"-"`
+	LastDelivery *WebhookDelivery `json:"-"`
+}
+
+// WebhookDelivery is a delivery of a webhook. Status_code is 0 when the receiver did not respond.
+type WebhookDelivery struct {
+	Delivered_at string
+	Event        string
+	Status       string
+	Status_code  int
+}
+
+// host is the host webhook payloads are delivered to.
+func (webhook Webhook) host() string {
+	parsed, err := url.Parse(webhook.Config.Url)
+	if err != nil {
+		return webhook.Config.Url
+	}
+
+	return parsed.Host
+}
+
+// delivered tells whether the last delivery of a webhook succeeded. Webhooks without deliveries count as delivered.
+func (webhook Webhook) delivered() bool {
+	if webhook.LastDelivery == nil {
+		return true
+	}
+
+	return webhook.LastDelivery.Status_code >= 200 && webhook.LastDelivery.Status_code < 300
+}
+
+// OrganizationWebhooks are the webhooks of an organization and its repositories. Unreadable are the
+// repositories whose webhooks the token may not list.
+type OrganizationWebhooks struct {
+	Webhooks   []Webhook
+	Unreadable []string
+}
+
+// getOrganizationWebhooks lists the webhooks of an organization and of every one of its repositories,
+// with their last delivery. Repositories the token may not read webhooks of are skipped and recorded.
+func getOrganizationWebhooks(org string, repositories []Repository) (*OrganizationWebhooks, error) {
+	client, err := gh.RESTClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := getWebhooks(client, "orgs/"+org, "organization")
+	if err != nil {
+		return nil, err
+	}
+
+	organizationWebhooks := &OrganizationWebhooks{Webhooks: webhooks}
+
+	for _, repository := range repositories {
+		repositoryWebhooks, err := getWebhooks(client, "repos/"+repository.Full_name, repository.Full_name)
+		if isPermissionError(err) {
+			organizationWebhooks.Unreadable = append(organizationWebhooks.Unreadable, repository.Full_name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		organizationWebhooks.Webhooks = append(organizationWebhooks.Webhooks, repositoryWebhooks...)
+	}
+
+	return organizationWebhooks, nil
+}
+
+// getWebhooks lists the webhooks under an orgs/ or repos/ REST path and requests the last delivery of each.
+func getWebhooks(client api.RESTClient, owner string, scope string) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	err := getAllPages(client, fmt.Sprintf("%s/hooks?per_page=%d", owner, restPageSize), func(body []byte) error {
+		var page []Webhook
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+
+		webhooks = append(webhooks, page...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Scope = scope
+
+		// deliveries are listed from the newest to the oldest, and are left out when the token may not read them
+		var deliveries []WebhookDelivery
+		err := client.Get(fmt.Sprintf("%s/hooks/%d/deliveries?per_page=1", owner, webhooks[i].Id), &deliveries)
+		if err != nil && !isPermissionError(err) {
+			return nil, err
+		}
+
+		if len(deliveries) > 0 {
+			webhooks[i].LastDelivery = &deliveries[0]
+		}
+	}
+
+	return webhooks, nil
+}
+
+// webhooksInventory lists the webhooks of an organization and its repositories, as a checklist to
+// verify after the transfer. Repositories whose webhooks could not be read are listed last.
+func webhooksInventory(org string, webhooks *OrganizationWebhooks) Inventory {
+	inventory := Inventory{
+		Name:    "Webhooks: " + org,
+		Columns: []string{"Scope", "Host", "Events", "Content Type", "Secret", "Active", "Last Delivery", "Last Status"},
+	}
+
+	for _, webhook := range webhooks.Webhooks {
+		var delivered, status string
+		if webhook.LastDelivery != nil {
+			delivered = webhook.LastDelivery.Delivered_at
+			status = fmt.Sprintf("%d %s", webhook.LastDelivery.Status_code, webhook.LastDelivery.Status)
+		}
+
+		inventory.Rows = append(inventory.Rows, []string{
+			webhook.Scope,
+			webhook.host(),
+			strings.Join(webhook.Events, ", "),
+			webhook.Config.Content_type,
+			strconv.FormatBool(webhook.Config.Secret != ""),
+			strconv.FormatBool(webhook.Active),
+			delivered,
+			status,
+		})
+	}
+
+	for _, repository := range webhooks.Unreadable {
+		inventory.Rows = append(inventory.Rows, []string{repository, "", "", "", "", "", "", "not readable"})
+	}
+
+	return inventory
+}
+
+var webhookDeliveriesRule = Rule{
+	ID:       "webhook-deliveries",
+	Policy:   "Webhook Deliveries",
+	Category: "integration",
+	Severity: SeverityLow,
+	Inputs:   []string{"organization.hooks", "organization.repositories.hooks", "organization.hooks.deliveries"},
+	Scopes:   []string{"admin:org_hook", "admin:repo_hook"},
+}
+
+// auditWebhooks lists the webhooks of an organization and its repositories and checks that they
+// deliver today, so that failures after the transfer can be told apart.
+func auditWebhooks(org string, repositories []Repository) ([]Finding, []Inventory) {
+	webhooks, err := getOrganizationWebhooks(org, repositories)
+	if err != nil {
+		return []Finding{uncollectedFinding(webhookDeliveriesRule, err)}, nil
+	}
+
+	finding := withRuleMetadata(webhookDeliveriesRule, compareWebhookDeliveries(webhooks))
+
+	return []Finding{finding}, []Inventory{webhooksInventory(org, webhooks)}
+}
+
+func compareWebhookDeliveries(webhooks *OrganizationWebhooks) Finding {
+	var active int
+	var failing []string
+	for _, webhook := range webhooks.Webhooks {
+		if !webhook.Active {
+			continue
+		}
+
+		active++
+		if !webhook.delivered() {
+			failing = append(failing, fmt.Sprintf("%s to %s (%d %s)", webhook.Scope, webhook.host(), webhook.LastDelivery.Status_code, webhook.LastDelivery.Status))
+		}
+	}
+
+	finding := Finding{
+		SourceValue:    fmt.Sprintf("%d active of %d webhooks", active, len(webhooks.Webhooks)),
+		EffectiveValue: fmt.Sprintf("%d webhooks to verify", active),
+	}
+
+	if len(failing) == 0 && len(webhooks.Unreadable) > 0 {
+		finding.Comment = fmt.Sprintf("The webhooks of %s could not be read. The last delivery of every other active webhook succeeded.", strings.Join(webhooks.Unreadable, ", "))
+		finding.Status = statusUnknown
+		finding.Remediation = "Check the webhooks of these repositories with an admin of each, or run the audit with a token that has the admin:repo_hook scope."
+		return finding
+	}
+
+	if active == 0 {
+		finding.Comment = "The Organization and its repositories have no active webhooks."
+		finding.EffectiveValue = "no webhooks to verify"
+		finding.Status = statusPass
+		return finding
+	}
+
+	if len(failing) == 0 {
+		finding.Comment = fmt.Sprintf("The last delivery of every active webhook succeeded. Check that the %d active webhooks still deliver after the transfer.", active)
+		finding.Status = statusPass
+		return finding
+	}
+
+	finding.Comment = fmt.Sprintf("The last delivery of the webhooks %s failed. Failures after the transfer cannot be told apart from these.", strings.Join(failing, ", "))
+	finding.Status = statusFail
+	finding.Remediation = "Fix or remove the failing webhooks before the transfer, then check that every active webhook still delivers after it."
+
+	return finding
+}
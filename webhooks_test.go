@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestCompareWebhookDeliveries(t *testing.T) {
+	var ci, chat, legacy Webhook
+	ci.Scope, ci.Active, ci.Events = "organization", true, []string{"push", "pull_request"}
+	ci.Config.Url, ci.Config.Content_type, ci.Config.Secret = "https://ci.octodemo.com/github?token=x", "json", "********"
+	ci.LastDelivery = &WebhookDelivery{Status: "OK", Status_code: 200}
+
+	chat.Scope, chat.Active = "octodemo/api", true
+	chat.Config.Url = "https://chat.octodemo.com/hooks"
+	chat.LastDelivery = &WebhookDelivery{Status: "Invalid HTTP Response: 503", Status_code: 503}
+
+	legacy.Scope = "octodemo/api"
+	legacy.Config.Url = "http://legacy.octodemo.com"
+	legacy.LastDelivery = &WebhookDelivery{Status_code: 0}
+
+	finding := compareWebhookDeliveries(&OrganizationWebhooks{Webhooks: []Webhook{ci, chat, legacy}, Unreadable: []string{"octodemo/vault"}})
+	want := "The last delivery of the webhooks octodemo/api to chat.octodemo.com (503 Invalid HTTP Response: 503) failed. Failures after the transfer cannot be told apart from these."
+	if finding.Status != statusFail || finding.Comment != want || finding.SourceValue != "2 active of 3 webhooks" {
+		t.Errorf("unexpected finding %+v", finding)
+	}
+
+	if finding := compareWebhookDeliveries(&OrganizationWebhooks{Webhooks: []Webhook{ci, legacy}}); finding.Status != statusPass {
+		t.Errorf("expected successful deliveries to pass, got %+v", finding)
+	}
+
+	webhooks := &OrganizationWebhooks{Webhooks: []Webhook{ci, legacy}, Unreadable: []string{"octodemo/vault"}}
+	if finding := compareWebhookDeliveries(webhooks); finding.Status != statusUnknown {
+		t.Errorf("expected unreadable repositories to be unknown, got %+v", finding)
+	}
+
+	rows := webhooksInventory("octodemo", webhooks).Rows
+	if rows[0][1] != "ci.octodemo.com" || rows[0][2] != "push, pull_request" || rows[0][4] != "true" || rows[0][7] != "200 OK" || rows[2][0] != "octodemo/vault" || rows[2][7] != "not readable" {
+		t.Errorf("unexpected inventory %v", rows)
+	}
+}